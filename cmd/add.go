@@ -22,17 +22,36 @@ THE SOFTWARE.
 package cmd
 
 import (
-	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/charmbracelet/huh"
 	"github.com/charmbracelet/log"
 	"github.com/sglyon/jupyteach/internal/model"
+	"github.com/sglyon/jupyteach/internal/notebook"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var (
+	addType        string
+	addTitle       string
+	addDescription string
+	addLecture     string
+	addFile        string
+	addVideoSource string
+	addYoutubeID   string
+	addVimeoID     string
+	addURL         string
+	addAvailableAt string
+	addMaxAttempts int
+	addTopics      string
+	addStartCode   string
+	addFromFile    string
 )
 
 type CreateOptions struct {
@@ -53,49 +72,106 @@ type NotebookOptions struct {
 	CommonOptions
 }
 
-func createLecture() error {
-	var lectureOptions LectureOptions
-	lectureOptions.AvailableAt = time.Now().Format(time.RFC3339)
+// isInteractive reports whether prompts can be shown, which needs a terminal
+// on both ends
+func isInteractive() bool {
+	return term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
+}
 
+// createLecture adds the lecture described by lectureOptions, first asking
+// for the fields ask allows
+func createLecture(lectureOptions LectureOptions, ask func(flag string) bool) error {
 	// Ensure `_course.yml` exists
 	courseMetadata, err := model.ParseCourseYaml(".")
 	if err != nil {
 		return err
 	}
-	sep := courseMetadata.Sep()
-
-	lectureForm := huh.NewForm(
-		huh.NewGroup(
-			huh.NewInput().Title("Lecture Title").Value(&lectureOptions.Title),
-			huh.NewInput().Title("Lecture Description").Value(&lectureOptions.Description),
-			huh.NewInput().
-				Title("Available At").
-				Value(&lectureOptions.AvailableAt).
-				Suggestions([]string{time.Now().Format(time.RFC3339)}).
-				Validate(func(s string) error {
-					_, err := time.Parse(time.RFC3339, s)
-					if err != nil {
-						return errors.New("Invalid date format. Must be in RFC3339 format (e.g. 2024-03-28T18:05:41Z)")
-					}
-					return nil
-				}),
-		),
-	)
-
-	if err := lectureForm.Run(); err != nil {
+	times, err := courseMetadata.TimeResolver(time.Now())
+	if err != nil {
 		return err
 	}
 
-	lectureOptions.Directory = model.Slugify(lectureOptions.Title, sep)
+	var fields []huh.Field
+	if ask("title") {
+		fields = append(fields, huh.NewInput().Title("Lecture Title").Value(&lectureOptions.Title))
+	}
+	if ask("description") {
+		fields = append(fields, huh.NewInput().Title("Lecture Description").Value(&lectureOptions.Description))
+	}
+	if ask("available-at") {
+		if lectureOptions.AvailableAt == "" {
+			lectureOptions.AvailableAt = times.Now.Format(time.RFC3339)
+		}
+		fields = append(fields, huh.NewInput().
+			Title("Available At").
+			Description("RFC3339, a local time like 2024-09-03 09:00, or start+2w 9am, next tue 9am").
+			Value(&lectureOptions.AvailableAt).
+			Suggestions([]string{times.Now.Format(time.RFC3339)}).
+			Validate(func(s string) error {
+				_, err := times.Resolve(s)
+				return err
+			}),
+		)
+	}
+	if len(fields) > 0 {
+		if err := huh.NewForm(huh.NewGroup(fields...)).Run(); err != nil {
+			return err
+		}
+	}
+
+	_, err = writeLecture(courseMetadata, times, lectureOptions)
+	return err
+}
+
+// checkNewLecture makes sure the lecture described by lectureOptions can be
+// added to the course and returns its directory
+func checkNewLecture(courseMetadata *model.CourseYaml, times *model.TimeResolver, lectureOptions LectureOptions) (string, error) {
+	if strings.TrimSpace(lectureOptions.Title) == "" {
+		return "", fmt.Errorf("A lecture needs a title")
+	}
+	if lectureOptions.AvailableAt != "" {
+		if _, err := times.Resolve(lectureOptions.AvailableAt); err != nil {
+			return "", fmt.Errorf("Available at of %q: %w", lectureOptions.Title, err)
+		}
+	}
+
+	directory := courseMetadata.SlugRules().Slugify(lectureOptions.Title)
+	if directory == "" {
+		return "", fmt.Errorf("Title %q has no letters or digits to name the lecture directory with", lectureOptions.Title)
+	}
+
+	// Make sure the lecture doesn't already exist. A directory without a
+	// _lecture.yml, holding the lecture's notebooks say, is used as is.
+	if _, err := courseMetadata.LectureIndex(directory); err == nil {
+		return "", fmt.Errorf("Lecture %s is already in _course.yml", directory)
+	}
+	if info, err := os.Stat(directory); err == nil && !info.IsDir() {
+		return "", fmt.Errorf("%s already exists and is not a directory", directory)
+	}
+	if _, err := os.Stat(filepath.Join(directory, "_lecture.yml")); err == nil {
+		return "", fmt.Errorf("Directory %s already has a _lecture.yml", directory)
+	}
+	return directory, nil
+}
+
+// writeLecture creates the directory and _lecture.yml of a new lecture and
+// adds it to _course.yml. It returns the directory.
+func writeLecture(courseMetadata *model.CourseYaml, times *model.TimeResolver, lectureOptions LectureOptions) (string, error) {
+	directory, err := checkNewLecture(courseMetadata, times, lectureOptions)
+	if err != nil {
+		return "", err
+	}
+	lectureOptions.Directory = directory
 
-	// Make sure directory doesn't already exist
-	if _, err := os.Stat(lectureOptions.Directory); !os.IsNotExist(err) {
-		return fmt.Errorf("Directory %s already exists", lectureOptions.Directory)
+	// Times relative to today would move every time the course is read, so
+	// pin them to the moment they were entered
+	if at, err := times.Resolve(lectureOptions.AvailableAt); err == nil && at.RelativeToNow {
+		lectureOptions.AvailableAt = at.Time.Format(time.RFC3339)
 	}
 
 	// Create directory
-	if err := os.Mkdir(lectureOptions.Directory, 0o755); err != nil {
-		return err
+	if err := os.MkdirAll(lectureOptions.Directory, 0o755); err != nil {
+		return "", err
 	}
 
 	newLecture := model.LectureYaml{
@@ -107,21 +183,21 @@ func createLecture() error {
 	// Write newLecture to lectureOptions.Directory/_lecture.yml
 	lecturePath := fmt.Sprintf("%s/_lecture.yml", lectureOptions.Directory)
 	if err := writeYaml(lecturePath, newLecture); err != nil {
-		return err
+		return "", err
 	}
 
 	// Add this lecture to _course.yml
 	newCourseLecture := model.CourseLectureYaml{
 		Directory:   lectureOptions.Directory,
-		AvailableAt: lectureOptions.AvailableAt, // current timestamp
+		AvailableAt: lectureOptions.AvailableAt,
 	}
 
 	courseMetadata.Lectures = append(courseMetadata.Lectures, newCourseLecture)
 	if err := writeYaml("_course.yml", courseMetadata); err != nil {
-		return err
+		return "", err
 	}
 
-	return nil
+	return lectureOptions.Directory, nil
 }
 
 // addCmd represents the add command
@@ -133,182 +209,359 @@ var addCmd = &cobra.Command{
 	This command will prompt you to select the type of
 	resource you want to add, guide you through defining all the necessary
 	fields, and finally create the .yml entries for you in _course.yml and/or
-	_lecture.yml`,
+	_lecture.yml
+
+	Every field can also be given with a flag, in which case it is not asked
+	for. Without a terminal nothing is asked and missing required values are
+	an error, so add can be used in scripts. With --from-file, the lectures
+	and content blocks listed in a yaml file are all added at once.`,
+	Example: `  jupyteach add --type lecture --title "Week 1" --available-at "start 9am"
+  jupyteach add --type notebook --lecture week-1 --file intro.ipynb
+  jupyteach add --type video --lecture week-1 --title Welcome --youtube-id dQw4w9WgXcQ
+  jupyteach add --from-file blocks.yml`,
+	Args: cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		var options CreateOptions
-		var contentBlock model.ContentBlockYaml
-		var videoSource string
-		var quizTopicsInput string
+		if addFromFile != "" {
+			if err := addEntriesFromFile(addFromFile, addLecture); err != nil {
+				log.Fatal(err)
+			}
+			return
+		}
 
-		lectureDirectory := "." // default to current directory
+		interactive := isInteractive()
+		ask := func(flag string) bool {
+			return interactive && !cmd.Flags().Changed(flag)
+		}
 
-		createTypeSelect := huh.NewSelect[string]().
-			Options(huh.NewOptions("lecture", "notebook", "markdown", "quiz", "video", "link")...).
-			Title("Choose resource type").
-			Value(&options.Type)
+		var options CreateOptions
+		options.Type = addType
+		if options.Type == "" {
+			if !interactive {
+				log.Fatal("--type is required when not run in a terminal")
+			}
+			createTypeSelect := huh.NewSelect[string]().
+				Options(huh.NewOptions("lecture", "notebook", "markdown", "quiz", "video", "link")...).
+				Title("Choose resource type").
+				Value(&options.Type)
 
-		if err := createTypeSelect.Run(); err != nil {
-			log.Fatal(err)
+			if err := createTypeSelect.Run(); err != nil {
+				log.Fatal(err)
+			}
 		}
 
 		if options.Type == "lecture" {
-			if err := createLecture(); err != nil {
+			lectureOptions := LectureOptions{
+				AvailableAt:   addAvailableAt,
+				CommonOptions: CommonOptions{Title: addTitle, Description: addDescription},
+			}
+			if err := createLecture(lectureOptions, ask); err != nil {
 				log.Fatal(err)
 			}
-			os.Exit(0)
+			return
 		}
 
-		contentBlock.Type = options.Type
-
-		if options.Type == "quiz" {
-			contentBlock.Quiz = model.Quiz{Questions: []model.Question{}} // initialize empty quiz
+		contentBlock := model.ContentBlockYaml{
+			Type:           options.Type,
+			Title:          addTitle,
+			Description:    addDescription,
+			Filename:       addFile,
+			URL:            addURL,
+			YoutubeVideoID: addYoutubeID,
+			VimeoVideoID:   addVimeoID,
 		}
 
-		// Ensure `_lecture.yml` exists
-		var lectureYaml *model.LectureYaml
-		var errFile error
-		lectureYaml, errFile = model.ParseLectureYaml("_lecture.yml")
-		if errFile != nil {
-			// try to let the user select an existing lecture
-			courseYaml, err := model.ParseCourseYaml(".")
-			if err != nil {
-				log.Fatal(err)
+		if options.Type == "quiz" {
+			contentBlock.Quiz = model.Quiz{
+				Questions:   []model.Question{}, // initialize empty quiz
+				MaxAttempts: addMaxAttempts,
+				Topics:      splitTopics(addTopics),
+				StartCode:   addStartCode,
 			}
+		}
 
-			options := make([]string, len(courseYaml.Lectures))
-			for i, lecture := range courseYaml.Lectures {
-				options[i] = lecture.Directory
-			}
-			lectureSelect := huh.NewSelect[string]().Options(huh.NewOptions(options...)...).Title("Select lecture").Value(&lectureDirectory)
+		lectureDirectory, lectureYaml, err := lectureForBlock(addLecture, interactive)
+		if err != nil {
+			log.Fatal(err)
+		}
 
-			if err := lectureSelect.Run(); err != nil {
+		form, finish, err := contentBlockForm(&contentBlock, lectureDirectory, blockFormOptions{videoSource: addVideoSource, ask: ask})
+		if err != nil {
+			log.Fatal(err)
+		}
+		if form != nil {
+			if err := form.Run(); err != nil {
 				log.Fatal(err)
 			}
+		}
+		finish()
 
-			// if we still can't find a lecture, bail
-			lectureYaml, errFile = model.ParseLectureYaml(filepath.Join(lectureDirectory, "_lecture.yml"))
-			if errFile != nil {
-				log.Fatal(errFile)
-			}
+		if err := checkNewBlock(contentBlock, lectureDirectory); err != nil {
+			log.Fatal(err)
 		}
 
-		commonContentGroup := huh.NewGroup(
-			huh.NewInput().Title(fmt.Sprintf("%s title (short)", options.Type)).Value(&contentBlock.Title),
-			huh.NewInput().Title(fmt.Sprintf("%s Description (longer)", options.Type)).Value(&contentBlock.Description),
-		)
+		lectureYaml.ContentBlocks = append(lectureYaml.ContentBlocks, contentBlock)
+		if err := writeYaml(filepath.Join(lectureDirectory, "_lecture.yml"), lectureYaml); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
 
-		videoForm := huh.NewForm(
-			commonContentGroup,
-			huh.NewGroup(
-				huh.NewSelect[string]().Title("Video source").Options(huh.NewOptions("url", "youtube", "vimeo")...).Value(&videoSource),
-			).WithHideFunc(func() bool { return options.Type != "video" }),
-			huh.NewGroup(
-				huh.NewInput().Title("Video URL").Value(&contentBlock.URL),
-			).WithHideFunc(func() bool { return videoSource != "url" }),
-			huh.NewGroup(
-				huh.NewInput().Title("YouTube Video ID").Value(&contentBlock.YoutubeVideoID),
-			).WithHideFunc(func() bool { return videoSource != "youtube" }),
-			huh.NewGroup(
-				huh.NewInput().Title("Vimeo Video ID").Value(&contentBlock.VimeoVideoID),
-			).WithHideFunc(func() bool { return videoSource != "vimeo" }),
-		)
+// lectureForBlock finds the lecture a content block is added to: the
+// directory given, the lecture in the current directory, or one picked from
+// _course.yml when prompts can be shown
+func lectureForBlock(directory string, interactive bool) (string, *model.LectureYaml, error) {
+	if directory != "" {
+		lectureYaml, err := model.ParseLectureYaml(filepath.Join(directory, "_lecture.yml"))
+		return directory, lectureYaml, err
+	}
 
-		linkForm := huh.NewForm(
-			commonContentGroup,
-			huh.NewGroup(
-				huh.NewInput().Title("URL").Value(&contentBlock.URL).Validate(func(s string) error {
-					if strings.HasPrefix(s, "http") {
-						return nil
-					} else {
-						return fmt.Errorf("Must provide valid URL that begins with http(s)://")
-					}
-				}),
-			),
-		)
+	// Ensure `_lecture.yml` exists
+	if lectureYaml, err := model.ParseLectureYaml("_lecture.yml"); err == nil {
+		return ".", lectureYaml, nil
+	}
+	if !interactive {
+		return "", nil, fmt.Errorf("--lecture is required outside of a lecture directory when not run in a terminal")
+	}
 
-		quizForm := huh.NewForm(
-			commonContentGroup,
-			huh.NewGroup(
-				huh.NewInput().Title("Start code").Description("Optional").Value(&contentBlock.Quiz.StartCode),
-				huh.NewInput().Title("Topics").Description("Optional, Comma separated").Value(&quizTopicsInput),
-				huh.NewSelect[int]().Title("Maximum attempts").Value(&contentBlock.Quiz.MaxAttempts).Options(huh.NewOptions(1, 2, 3, 4, 5, 1000)...),
-			),
-		)
+	// try to let the user select an existing lecture
+	courseYaml, err := model.ParseCourseYaml(".")
+	if err != nil {
+		return "", nil, err
+	}
 
-		nbFiles, err := ListFilesInDirectory(lectureDirectory, []string{".ipynb"})
-		if err != nil {
-			log.Fatal(err)
+	options := make([]string, len(courseYaml.Lectures))
+	for i, lecture := range courseYaml.Lectures {
+		options[i] = lecture.Directory
+	}
+	lectureSelect := huh.NewSelect[string]().Options(huh.NewOptions(options...)...).Title("Select lecture").Value(&directory)
+
+	if err := lectureSelect.Run(); err != nil {
+		return "", nil, err
+	}
+
+	// if we still can't find a lecture, bail
+	lectureYaml, err := model.ParseLectureYaml(filepath.Join(directory, "_lecture.yml"))
+	return directory, lectureYaml, err
+}
+
+// checkNewBlock makes sure a content block for the lecture in
+// lectureDirectory has what its type needs
+func checkNewBlock(cb model.ContentBlockYaml, lectureDirectory string) error {
+	if err := cb.Validate(); err != nil {
+		return err
+	}
+	if cb.Type == "link" {
+		if err := validateURL(cb.URL); err != nil {
+			return err
 		}
-		if len(nbFiles) == 0 && options.Type == "notebook" {
-			log.Fatal("No notebooks found in current directory. Notebooks must have .ipynb extension")
+	}
+	if cb.Filename != "" {
+		if _, err := os.Stat(filepath.Join(lectureDirectory, cb.Filename)); err != nil {
+			return fmt.Errorf("File %s not found in %s", cb.Filename, lectureDirectory)
 		}
+	}
+	return nil
+}
 
-		notebookForm := huh.NewForm(
-			commonContentGroup,
-			huh.NewGroup(
-				huh.NewSelect[string]().
-					Options(huh.NewOptions(nbFiles...)...).
-					Title("Notebook file").
-					Value(&contentBlock.Filename),
-			),
-		)
+func validateURL(s string) error {
+	if strings.HasPrefix(s, "http") {
+		return nil
+	} else {
+		return fmt.Errorf("Must provide valid URL that begins with http(s)://")
+	}
+}
 
-		mdFiles, err := ListFilesInDirectory(lectureDirectory, []string{".md"})
-		if err != nil {
-			log.Fatal(err)
+// splitTopics turns a comma separated list of topics into a slice
+func splitTopics(s string) []string {
+	topics := make([]string, 0)
+	for _, topic := range strings.Split(s, ",") {
+		if topic = strings.TrimSpace(topic); topic != "" {
+			topics = append(topics, topic)
 		}
+	}
+	return topics
+}
+
+// blockFormOptions controls what contentBlockForm asks for
+type blockFormOptions struct {
+	// videoSource is the video source to start from, worked out from the
+	// block when empty
+	videoSource string
 
-		if len(mdFiles) == 0 && options.Type == "markdown" {
-			log.Fatal("No markdown files found in current directory. Markdown files must have .md extension")
+	// ask reports whether to ask for the field set by the add flag of the
+	// given name, everything is asked for when nil
+	ask func(flag string) bool
+}
+
+// contentBlockForm builds the form asking for the fields of a content block
+// of type cb.Type in lectureDirectory. The form starts from the values
+// already in cb, so it is used both to add and to edit blocks. The form is
+// nil when there is nothing to ask. The returned function must be called
+// once the form has run to tidy up what was entered.
+func contentBlockForm(cb *model.ContentBlockYaml, lectureDirectory string, opts blockFormOptions) (*huh.Form, func(), error) {
+	ask := opts.ask
+	if ask == nil {
+		ask = func(string) bool { return true }
+	}
+	videoSource := opts.videoSource
+	switch {
+	case videoSource != "":
+		if !slices.Contains(model.VideoSources[:], videoSource) {
+			return nil, nil, fmt.Errorf("Video source must be one of %s", strings.Join(model.VideoSources[:], ", "))
 		}
+	case cb.YoutubeVideoID != "":
+		videoSource = "youtube"
+	case cb.VimeoVideoID != "":
+		videoSource = "vimeo"
+	default:
+		videoSource = "url"
+	}
+	quizTopicsInput := strings.Join(cb.Quiz.Topics, ", ")
+	if cb.Filename != "" {
+		// the file options are listed with the lecture directory in front
+		cb.Filename = filepath.Join(lectureDirectory, cb.Filename)
+	}
 
-		mdForm := huh.NewForm(
-			commonContentGroup,
-			huh.NewGroup(
-				huh.NewSelect[string]().
-					Options(huh.NewOptions(mdFiles...)...).
-					Title("Notebook file").
-					Value(&contentBlock.Filename),
-			),
-		)
+	// groups holds the groups with something to ask
+	var groups []*huh.Group
+	group := func(hide func() bool, fields ...huh.Field) {
+		if len(fields) == 0 {
+			return
+		}
+		g := huh.NewGroup(fields...)
+		if hide != nil {
+			g = g.WithHideFunc(hide)
+		}
+		groups = append(groups, g)
+	}
+	input := func(flag, title string, value *string) []huh.Field {
+		if !ask(flag) {
+			return nil
+		}
+		return []huh.Field{huh.NewInput().Title(title).Value(value)}
+	}
 
-		var form *huh.Form
-		switch options.Type {
-		case "notebook":
-			form = notebookForm
-		case "markdown":
-			form = mdForm
-		case "video":
-			form = videoForm
-		case "link":
-			form = linkForm
-		case "quiz":
-			form = quizForm
+	group(nil, append(
+		input("title", fmt.Sprintf("%s title (short)", cb.Type), &cb.Title),
+		input("description", fmt.Sprintf("%s Description (longer)", cb.Type), &cb.Description)...,
+	)...)
+
+	switch cb.Type {
+	case "video":
+		if ask("video-source") {
+			group(nil, huh.NewSelect[string]().Title("Video source").Options(huh.NewOptions(model.VideoSources[:]...)...).Value(&videoSource))
 		}
+		group(func() bool { return videoSource != "url" }, input("url", "Video URL", &cb.URL)...)
+		group(func() bool { return videoSource != "youtube" }, input("youtube-id", "YouTube Video ID", &cb.YoutubeVideoID)...)
+		group(func() bool { return videoSource != "vimeo" }, input("vimeo-id", "Vimeo Video ID", &cb.VimeoVideoID)...)
 
-		if err := form.Run(); err != nil {
-			log.Fatal(err)
+	case "link":
+		if ask("url") {
+			group(nil, huh.NewInput().Title("URL").Value(&cb.URL).Validate(validateURL))
+		}
+
+	case "quiz":
+		if model.IsQuizMarkdown(cb.Filename) {
+			// the rest of a markdown quiz is edited in its file
+			break
+		}
+		var fields []huh.Field
+		if ask("start-code") {
+			fields = append(fields, huh.NewInput().Title("Start code").Description("Optional").Value(&cb.Quiz.StartCode))
+		}
+		if ask("topics") {
+			fields = append(fields, huh.NewInput().Title("Topics").Description("Optional, Comma separated").Value(&quizTopicsInput))
+		}
+		if ask("max-attempts") {
+			attempts := []int{1, 2, 3, 4, 5, 1000}
+			if cb.Quiz.MaxAttempts != 0 && !slices.Contains(attempts, cb.Quiz.MaxAttempts) {
+				attempts = append(attempts, cb.Quiz.MaxAttempts)
+				slices.Sort(attempts)
+			}
+			fields = append(fields, huh.NewSelect[int]().Title("Maximum attempts").Value(&cb.Quiz.MaxAttempts).Options(huh.NewOptions(attempts...)...))
 		}
+		group(nil, fields...)
+
+	case "notebook", "markdown":
+		if !ask("file") {
+			break
+		}
+		ext, title := ".ipynb", "Notebook file"
+		if cb.Type == "markdown" {
+			ext, title = ".md", "Markdown file"
+		}
+		files, err := ListFilesInDirectory(lectureDirectory, []string{ext})
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(files) == 0 {
+			return nil, nil, fmt.Errorf("No %s files found in %s. They must have %s extension", cb.Type, lectureDirectory, ext)
+		}
+		group(nil, huh.NewSelect[string]().
+			Options(huh.NewOptions(files...)...).
+			Title(title).
+			Value(&cb.Filename),
+		)
+
+	default:
+		return nil, nil, fmt.Errorf("unknown content block type %q", cb.Type)
+	}
 
-		// Post processing
-		switch options.Type {
+	var form *huh.Form
+	if len(groups) > 0 {
+		form = huh.NewForm(groups...)
+	}
+
+	finish := func() {
+		switch cb.Type {
 		case "quiz":
-			contentBlock.Quiz.Topics = make([]string, 0)
-			for _, topic := range strings.Split(quizTopicsInput, ",") {
-				contentBlock.Quiz.Topics = append(contentBlock.Quiz.Topics, strings.TrimSpace(topic))
+			if !model.IsQuizMarkdown(cb.Filename) {
+				cb.Quiz.Topics = splitTopics(quizTopicsInput)
 			}
 		case "notebook", "markdown":
-			// strip lecture directory prefix from filename
-			contentBlock.Filename = strings.TrimPrefix(contentBlock.Filename, lectureDirectory+string(filepath.Separator))
+			if cb.Type == "notebook" && cb.Title == "" {
+				// default to the title of the notebook itself
+				if nb, err := notebook.ReadFile(cb.Filename); err == nil {
+					cb.Title = nb.Title()
+				}
+			}
+		case "video":
+			// only the chosen source is kept
+			if videoSource != "url" {
+				cb.URL = ""
+			}
+			if videoSource != "youtube" {
+				cb.YoutubeVideoID = ""
+			}
+			if videoSource != "vimeo" {
+				cb.VimeoVideoID = ""
+			}
 		}
-
-		lectureYaml.ContentBlocks = append(lectureYaml.ContentBlocks, contentBlock)
-		if err := writeYaml(filepath.Join(lectureDirectory, "_lecture.yml"), lectureYaml); err != nil {
-			log.Fatal(err)
+		if cb.Filename != "" {
+			// strip lecture directory prefix from filename
+			if rel, err := filepath.Rel(lectureDirectory, cb.Filename); err == nil {
+				cb.Filename = filepath.ToSlash(rel)
+			}
 		}
-	},
+	}
+	return form, finish, nil
 }
 
 func init() {
 	rootCmd.AddCommand(addCmd)
+
+	addCmd.Flags().StringVar(&addType, "type", "", "type of resource: lecture, notebook, markdown, quiz, video or link")
+	addCmd.Flags().StringVar(&addTitle, "title", "", "title of the lecture or content block")
+	addCmd.Flags().StringVar(&addDescription, "description", "", "description of the lecture or content block")
+	addCmd.Flags().StringVar(&addLecture, "lecture", "", "directory of the lecture to add the content block to")
+	addCmd.Flags().StringVar(&addFile, "file", "", "notebook, markdown or markdown quiz file, relative to the lecture directory")
+	addCmd.Flags().StringVar(&addVideoSource, "video-source", "", "where a video is hosted: url, youtube or vimeo (default from the id or url given)")
+	addCmd.Flags().StringVar(&addYoutubeID, "youtube-id", "", "id of a YouTube video")
+	addCmd.Flags().StringVar(&addVimeoID, "vimeo-id", "", "id of a Vimeo video")
+	addCmd.Flags().StringVar(&addURL, "url", "", "address of a link or video")
+	addCmd.Flags().StringVar(&addAvailableAt, "available-at", "", "when a lecture becomes available, e.g. 2024-09-03 09:00 or start+2w 9am")
+	addCmd.Flags().IntVar(&addMaxAttempts, "max-attempts", 0, "maximum attempts at a quiz")
+	addCmd.Flags().StringVar(&addTopics, "topics", "", "comma separated topics of a quiz")
+	addCmd.Flags().StringVar(&addStartCode, "start-code", "", "code a quiz starts from")
+	addCmd.Flags().StringVar(&addFromFile, "from-file", "", "add every lecture and content block listed in this yaml file")
 }
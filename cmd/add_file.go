@@ -0,0 +1,175 @@
+/*
+Copyright © 2024 Spencer Lyon spencerlyon2@gmail.com
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/sglyon/jupyteach/internal/model"
+	"github.com/sglyon/jupyteach/internal/notebook"
+	"gopkg.in/yaml.v2"
+)
+
+// addEntry is one resource in an add --from-file list: a lecture (type
+// lecture) or a content block, written as in _lecture.yml, with the
+// directory of the lecture it goes in
+type addEntry struct {
+	Lecture                string `yaml:"lecture,omitempty"`
+	AvailableAt            string `yaml:"available_at,omitempty"`
+	model.ContentBlockYaml `yaml:",inline"`
+}
+
+// addPlan is what addEntriesFromFile found it has to do
+type addPlan struct {
+	course *model.CourseYaml
+	times  *model.TimeResolver
+
+	// lectures are the directories the blocks go in, in the order of the file
+	lectures []string
+}
+
+// addEntriesFromFile adds every lecture and content block listed in the yaml
+// file at path. A block goes in its lecture, the lecture added last before
+// it in the file, defaultLecture, or the lecture in the current directory.
+// Every entry is checked before anything is written.
+func addEntriesFromFile(path, defaultLecture string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var entries []addEntry
+	if err := yaml.UnmarshalStrict(data, &entries); err != nil {
+		return fmt.Errorf("unable to parse %s: %w", path, err)
+	}
+
+	plan := &addPlan{}
+	if err := plan.check(path, entries, defaultLecture); err != nil {
+		return err
+	}
+
+	lectures := make(map[string]*model.LectureYaml, len(plan.lectures))
+	nLectures, nBlocks := 0, 0
+	for i, e := range entries {
+		if e.Type == "lecture" {
+			if _, err := writeLecture(plan.course, plan.times, e.lectureOptions()); err != nil {
+				return err
+			}
+			nLectures++
+			continue
+		}
+
+		directory := plan.lectures[i]
+		lecture, ok := lectures[directory]
+		if !ok {
+			lecture, err = model.ParseLectureYaml(filepath.Join(directory, "_lecture.yml"))
+			if err != nil {
+				return err
+			}
+			lectures[directory] = lecture
+		}
+		lecture.ContentBlocks = append(lecture.ContentBlocks, e.contentBlock(directory))
+		nBlocks++
+	}
+
+	for _, directory := range plan.lectures {
+		lecture, ok := lectures[directory]
+		if !ok {
+			continue
+		}
+		if err := writeYaml(filepath.Join(directory, "_lecture.yml"), lecture); err != nil {
+			return err
+		}
+		delete(lectures, directory)
+	}
+	log.Info("Added from file", "lectures", nLectures, "blocks", nBlocks)
+	return nil
+}
+
+// check goes through the entries without writing anything, recording the
+// lecture each block goes in
+func (p *addPlan) check(path string, entries []addEntry, defaultLecture string) error {
+	current := defaultLecture
+	if current == "" {
+		if _, err := os.Stat("_lecture.yml"); err == nil {
+			current = "."
+		}
+	}
+	known := map[string]bool{}
+	p.lectures = make([]string, len(entries))
+
+	for i, e := range entries {
+		where := fmt.Sprintf("%s, entry %d", path, i+1)
+
+		if e.Type == "lecture" {
+			if p.course == nil {
+				course, err := model.ParseCourseYaml(".")
+				if err != nil {
+					return err
+				}
+				times, err := course.TimeResolver(time.Now())
+				if err != nil {
+					return err
+				}
+				p.course, p.times = course, times
+			}
+			directory, err := checkNewLecture(p.course, p.times, e.lectureOptions())
+			if err != nil {
+				return fmt.Errorf("%s: %w", where, err)
+			}
+			if known[directory] {
+				return fmt.Errorf("%s: lecture %s is added twice", where, directory)
+			}
+			known[directory] = true
+			current = directory
+			continue
+		}
+
+		directory := e.Lecture
+		if directory == "" {
+			directory = current
+		}
+		if directory == "" {
+			return fmt.Errorf("%s: no lecture to add %s to, set lecture or --lecture", where, blockLabel(e.ContentBlockYaml))
+		}
+		if !known[directory] {
+			if _, err := model.ParseLectureYaml(filepath.Join(directory, "_lecture.yml")); err != nil {
+				return fmt.Errorf("%s: %w", where, err)
+			}
+			known[directory] = true
+		}
+		if err := checkNewBlock(e.ContentBlockYaml, directory); err != nil {
+			return fmt.Errorf("%s: %w", where, err)
+		}
+		p.lectures[i] = directory
+	}
+	return nil
+}
+
+// lectureOptions are the options of a lecture entry
+func (e addEntry) lectureOptions() LectureOptions {
+	return LectureOptions{
+		AvailableAt:   e.AvailableAt,
+		CommonOptions: CommonOptions{Title: e.Title, Description: e.Description},
+	}
+}
+
+// contentBlock is the block of an entry, filled in the way jupyteach add
+// fills it in
+func (e addEntry) contentBlock(directory string) model.ContentBlockYaml {
+	cb := e.ContentBlockYaml
+	if cb.Type == "quiz" && cb.Quiz.Questions == nil {
+		cb.Quiz.Questions = []model.Question{}
+	}
+	if cb.Type == "notebook" && cb.Title == "" {
+		// default to the title of the notebook itself
+		if nb, err := notebook.ReadFile(filepath.Join(directory, cb.Filename)); err == nil {
+			cb.Title = nb.Title()
+		}
+	}
+	return cb
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sglyon/jupyteach/internal/model"
+)
+
+func TestAddEntriesFromFile(t *testing.T) {
+	dir := t.TempDir()
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(cwd)
+
+	files := map[string]string{
+		"_course.yml":        "slug: demo\nstart_date: 2027-01-12\n",
+		"week-2/notes.md":    "# Notes\n",
+		"intro/_lecture.yml": "title: Intro\ncontent_blocks: []\n",
+		"bad.yml":            "- type: lecture\n  title: Week 2\n- type: markdown\n  filename: missing.md\n",
+		"blocks.yml":         "- type: link\n  lecture: intro\n  url: https://example.com\n- type: lecture\n  title: Week 2\n  available_at: start+1w\n- type: markdown\n  filename: notes.md\n",
+	}
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := addEntriesFromFile("bad.yml", ""); err == nil || !strings.Contains(err.Error(), "entry 2") {
+		t.Errorf("expected an error for entry 2, got %v", err)
+	}
+	if _, err := os.Stat("week-2/_lecture.yml"); err == nil {
+		t.Error("a lecture was written although the file has an error")
+	}
+
+	if err := addEntriesFromFile("blocks.yml", ""); err != nil {
+		t.Fatal(err)
+	}
+	course, err := model.ParseCourseYaml(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(course.Lectures) != 1 || course.Lectures[0].Directory != "week-2" || course.Lectures[0].AvailableAt != "start+1w" {
+		t.Errorf("lectures are %+v", course.Lectures)
+	}
+	intro, err := model.ParseLectureYaml("intro/_lecture.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(intro.ContentBlocks) != 1 || intro.ContentBlocks[0].URL != "https://example.com" {
+		t.Errorf("intro blocks are %+v", intro.ContentBlocks)
+	}
+	week2, err := model.ParseLectureYaml("week-2/_lecture.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if week2.Title != "Week 2" || len(week2.ContentBlocks) != 1 || week2.ContentBlocks[0].Filename != "notes.md" {
+		t.Errorf("week-2 is %+v", week2)
+	}
+}
@@ -0,0 +1,284 @@
+/*
+Copyright © 2024 Spencer Lyon spencerlyon2@gmail.com
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/sglyon/jupyteach/internal/git"
+	"github.com/sglyon/jupyteach/internal/model"
+	"github.com/spf13/cobra"
+)
+
+var (
+	blockMoveBefore string
+	blockMoveAfter  string
+	blockRemoveFile bool
+	blockRemoveYes  bool
+)
+
+// blockCmd represents the block command
+var blockCmd = &cobra.Command{
+	Use:   "block",
+	Short: "Work with the content blocks of a lecture",
+	Long: `Work with the content blocks of a lecture.
+
+	Blocks are named by their number in the lecture, counting from 1, or by
+	their title. See the subcommands for what can be done.`,
+}
+
+// blockListCmd represents the block list command
+var blockListCmd = &cobra.Command{
+	Use:   "list {lecture_directory}",
+	Short: "List the content blocks of a lecture",
+	Long: `List the content blocks of a lecture in order, with their number, type,
+	title, file or url, and the ids the server gave them.`,
+	Example: `  jupyteach block list intro`,
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		path, err := cmd.Flags().GetString("path")
+		if err != nil {
+			logger.Fatalf("Must provide a path")
+		}
+		lecture, err := model.ParseLectureYaml(filepath.Join(path, args[0], "_lecture.yml"))
+		if err != nil {
+			logger.Fatal(err)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "#\tTYPE\tTITLE\tSOURCE\tCONTENT_BLOCK_ID\tLECTURE_CONTENT_ID")
+		for i, cb := range lecture.ContentBlocks {
+			fmt.Fprintf(
+				w, "%d\t%s\t%s\t%s\t%s\t%s\n",
+				i+1, cb.Type, cb.Title, blockSource(cb), formatID(cb.ContentBlockID), formatID(cb.LectureContentID),
+			)
+		}
+		w.Flush()
+	},
+}
+
+// blockEditCmd represents the block edit command
+var blockEditCmd = &cobra.Command{
+	Use:   "edit {lecture_directory} {block}",
+	Short: "Edit a content block",
+	Long: `Edit a content block with the same form as jupyteach add, filled in with
+	its current values.
+
+	The block keeps its ids, so the server updates it on the next push.`,
+	Example: `  jupyteach block edit intro 2
+  jupyteach block edit intro "Practice quiz"`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		path, err := cmd.Flags().GetString("path")
+		if err != nil {
+			logger.Fatalf("Must provide a path")
+		}
+		lectureDir := filepath.Join(path, args[0])
+		lectureFile := filepath.Join(lectureDir, "_lecture.yml")
+		lecture, err := model.ParseLectureYaml(lectureFile)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		i, err := lecture.BlockIndex(args[1])
+		if err != nil {
+			logger.Fatal(err)
+		}
+
+		cb := lecture.ContentBlocks[i]
+		form, finish, err := contentBlockForm(&cb, lectureDir, blockFormOptions{})
+		if err != nil {
+			logger.Fatal(err)
+		}
+		if err := form.Run(); err != nil {
+			logger.Fatal(err)
+		}
+		finish()
+
+		lecture.ContentBlocks[i] = cb
+		if err := lecture.WriteYaml(lectureFile); err != nil {
+			logger.Fatal(err)
+		}
+		logger.Info("Updated content block", "lecture", args[0], "block", i+1)
+	},
+}
+
+// blockRemoveCmd represents the block remove command
+var blockRemoveCmd = &cobra.Command{
+	Use:   "remove {lecture_directory} {block}",
+	Short: "Remove a content block from a lecture",
+	Long: `Remove a content block from a lecture's _lecture.yml.
+
+	The notebook or markdown file of the block stays on disk unless
+	--delete-file is given, in which case it is deleted with git rm. A file
+	another block of the lecture still uses is never deleted. A block the
+	server knows about is removed there on the next push.`,
+	Example: `  jupyteach block remove intro 3 --delete-file`,
+	Args:    cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		path, err := cmd.Flags().GetString("path")
+		if err != nil {
+			logger.Fatalf("Must provide a path")
+		}
+		lectureFile := filepath.Join(path, args[0], "_lecture.yml")
+		lecture, err := model.ParseLectureYaml(lectureFile)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		i, err := lecture.BlockIndex(args[1])
+		if err != nil {
+			logger.Fatal(err)
+		}
+
+		if !blockRemoveYes {
+			ok, err := confirm(fmt.Sprintf("Remove %s from %s?", blockLabel(lecture.ContentBlocks[i]), args[0]))
+			if err != nil {
+				logger.Fatal(err)
+			}
+			if !ok {
+				return
+			}
+		}
+
+		// write the lecture first so a failed git rm never leaves a block
+		// pointing at a deleted file
+		cb := lecture.RemoveBlock(i)
+		if err := lecture.WriteYaml(lectureFile); err != nil {
+			logger.Fatal(err)
+		}
+		if blockRemoveFile && cb.Filename != "" {
+			if lecture.UsesFile(cb.Filename) {
+				logger.Warn("Kept the file, another block uses it", "file", cb.Filename)
+			} else if err := git.Remove(path, filepath.Join(args[0], cb.Filename)); err != nil {
+				logger.Fatal(err)
+			}
+		}
+		logger.Info("Removed content block", "lecture", args[0], "block", blockLabel(cb))
+	},
+}
+
+// blockMoveCmd represents the block move command
+var blockMoveCmd = &cobra.Command{
+	Use:   "move {lecture_directory} {block} --before|--after {block}",
+	Short: "Move a content block to another place in its lecture",
+	Long: `Move a content block to just before or just after another block of
+	the same lecture.
+
+	Blocks are shown in the order of content_blocks in _lecture.yml, which is
+	rewritten. Run jupyteach block reorder to move blocks around
+	interactively.`,
+	Example: `  jupyteach block move intro "Practice quiz" --after 1
+  jupyteach block move intro 4 --before 2`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		path, err := cmd.Flags().GetString("path")
+		if err != nil {
+			logger.Fatalf("Must provide a path")
+		}
+		lectureFile := filepath.Join(path, args[0], "_lecture.yml")
+		lecture, err := model.ParseLectureYaml(lectureFile)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		target, after := moveTarget(blockMoveBefore, blockMoveAfter)
+		if err := lecture.MoveBlock(args[1], target, after); err != nil {
+			logger.Fatal(err)
+		}
+		if err := lecture.WriteYaml(lectureFile); err != nil {
+			logger.Fatal(err)
+		}
+		logger.Info("Moved content block", "lecture", args[0], "block", args[1])
+	},
+}
+
+// blockReorderCmd represents the block reorder command
+var blockReorderCmd = &cobra.Command{
+	Use:   "reorder {lecture_directory}",
+	Short: "Put the content blocks of a lecture in a new order interactively",
+	Long: `Put the content blocks of a lecture in a new order interactively.
+
+	Pick a block with the arrow keys, grab it with space and move it with the
+	arrow keys, or move it at once with shift and the arrow keys. Enter saves
+	the new order to _lecture.yml and q quits without saving.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		path, err := cmd.Flags().GetString("path")
+		if err != nil {
+			logger.Fatalf("Must provide a path")
+		}
+		lectureFile := filepath.Join(path, args[0], "_lecture.yml")
+		lecture, err := model.ParseLectureYaml(lectureFile)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		items := make([]string, len(lecture.ContentBlocks))
+		for i, cb := range lecture.ContentBlocks {
+			items[i] = blockLabel(cb)
+		}
+
+		order, save, err := runReorder("Content blocks of "+lectureTitle(path, args[0]), items)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		if !save {
+			return
+		}
+		if err := lecture.ReorderBlocks(order); err != nil {
+			logger.Fatal(err)
+		}
+		if err := lecture.WriteYaml(lectureFile); err != nil {
+			logger.Fatal(err)
+		}
+		logger.Info("Saved the content block order", "lecture", args[0])
+	},
+}
+
+// blockLabel describes a content block in a line
+func blockLabel(cb model.ContentBlockYaml) string {
+	title := cb.Title
+	if title == "" {
+		title = "(untitled)"
+	}
+	return fmt.Sprintf("%s [%s]", title, cb.Type)
+}
+
+// blockSource is where the content of a block comes from: its file, video
+// or url
+func blockSource(cb model.ContentBlockYaml) string {
+	if cb.Filename != "" {
+		return cb.Filename
+	}
+	if cb.Type == "video" {
+		return cb.VideoURL()
+	}
+	return cb.URL
+}
+
+// formatID shows an id the server assigned, or - when there is none yet
+func formatID(id int) string {
+	if id == 0 {
+		return "-"
+	}
+	return strconv.Itoa(id)
+}
+
+func init() {
+	rootCmd.AddCommand(blockCmd)
+	blockCmd.AddCommand(blockListCmd)
+	blockCmd.AddCommand(blockEditCmd)
+	blockCmd.AddCommand(blockRemoveCmd)
+	blockCmd.AddCommand(blockMoveCmd)
+	blockCmd.AddCommand(blockReorderCmd)
+
+	blockMoveCmd.Flags().StringVar(&blockMoveBefore, "before", "", "block to move before")
+	blockMoveCmd.Flags().StringVar(&blockMoveAfter, "after", "", "block to move after")
+	blockMoveCmd.MarkFlagsMutuallyExclusive("before", "after")
+	blockMoveCmd.MarkFlagsOneRequired("before", "after")
+
+	blockRemoveCmd.Flags().BoolVar(&blockRemoveFile, "delete-file", false, "also delete the block's notebook or markdown file with git rm")
+	blockRemoveCmd.Flags().BoolVarP(&blockRemoveYes, "yes", "y", false, "remove without asking for confirmation")
+}
@@ -0,0 +1,71 @@
+/*
+Copyright © 2024 Spencer Lyon spencerlyon2@gmail.com
+*/
+package cmd
+
+import (
+	"path/filepath"
+
+	"github.com/sglyon/jupyteach/internal/site"
+	"github.com/spf13/cobra"
+)
+
+var (
+	buildOut          string
+	buildAsOf         string
+	buildStripOutputs bool
+)
+
+// buildCmd represents the build command
+var buildCmd = &cobra.Command{
+	Use:   "build",
+	Short: "Build the course as a static website",
+	Long: `Build the course as a static website that can be hosted anywhere, for
+	example on GitHub Pages as a public mirror of an open course.
+
+	The site has the same pages as ` + "`jupyteach preview`" + `: the list of
+	lectures, and for each lecture its markdown, notebooks, embedded videos,
+	links and quizzes. Quizzes are shown without their solutions, and neither
+	_lecture.yml nor markdown quiz files are copied to the site.
+
+	Pass --as-of to only publish the lectures visible at that time.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		path, err := cmd.Flags().GetString("path")
+		if err != nil {
+			logger.Fatalf("Must provide a path")
+		}
+
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		absOut, err := filepath.Abs(buildOut)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		if absOut == absPath {
+			logger.Fatal("--out must not be the course directory")
+		}
+
+		s := &site.Site{Dir: path, StripOutputs: buildStripOutputs}
+		if buildAsOf != "" {
+			if s.AsOf, err = parseAsOf(path, buildAsOf); err != nil {
+				logger.Fatal(err)
+			}
+		}
+		n, err := s.Build(buildOut)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		logger.Infof("Wrote %d files to %s", n, buildOut)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(buildCmd)
+
+	buildCmd.Flags().StringVarP(&buildOut, "out", "o", "site", "directory to write the site to")
+	buildCmd.Flags().StringVar(&buildAsOf, "as-of", "", "only include lectures visible at this time, e.g. \"2024-09-02 08:00\" or now")
+	buildCmd.Flags().BoolVar(&buildStripOutputs, "strip-outputs", false, "hide notebook outputs")
+}
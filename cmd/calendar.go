@@ -0,0 +1,79 @@
+/*
+Copyright © 2024 Spencer Lyon spencerlyon2@gmail.com
+*/
+package cmd
+
+import (
+	"github.com/sglyon/jupyteach/internal/ical"
+	"github.com/sglyon/jupyteach/internal/model"
+	"github.com/spf13/cobra"
+)
+
+var (
+	calendarOut   string
+	calendarForce bool
+)
+
+// calendarCmd represents the calendar command
+var calendarCmd = &cobra.Command{
+	Use:   "calendar",
+	Short: "Work with the course calendar",
+	Long: `Work with the course calendar.
+
+	See the subcommands for what can be done.`,
+}
+
+// calendarExportCmd represents the calendar export command
+var calendarExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export lecture releases and due dates as an iCalendar file",
+	Long: `Export lecture releases and due dates as an iCalendar (.ics) file.
+
+	Every lecture with an available_at in _course.yml becomes an event titled
+	after the title in its _lecture.yml. Every quiz, and every block taking
+	uploads, with a cli_due_at in _lecture.yml becomes an event at its due date.
+
+	Events keep the same identifiers between exports, so calendar apps that
+	import or subscribe to the file again update them in place. Due dates are
+	identified by the block's server id, or else its file or title, so moving
+	a block within its lecture keeps its event.
+
+	The file is written to <course slug>.ics next to the course directory
+	unless --out is given.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		path, err := cmd.Flags().GetString("path")
+		if err != nil {
+			logger.Fatalf("Must provide a path")
+		}
+
+		course, err := model.ParseCourseYaml(path)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		out, err := outPath(calendarOut, calendarForce, path, course, ".ics")
+		if err != nil {
+			logger.Fatal(err)
+		}
+
+		cal, err := ical.Build(path, course)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		if len(cal.Events) == 0 {
+			logger.Warn("The course has no release or due dates, set available_at in _course.yml or cli_due_at in _lecture.yml")
+		}
+		if err := writeExport(out, cal.Write); err != nil {
+			logger.Fatal(err)
+		}
+		logger.Info("Exported course calendar", "file", out, "events", len(cal.Events))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(calendarCmd)
+	calendarCmd.AddCommand(calendarExportCmd)
+
+	calendarExportCmd.Flags().StringVarP(&calendarOut, "out", "o", "", "file to write the calendar to")
+	calendarExportCmd.Flags().BoolVar(&calendarForce, "force", false, "overwrite an existing calendar file")
+}
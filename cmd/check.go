@@ -4,22 +4,46 @@ Copyright © 2024 Spencer Lyon spencerlyon2@gmail.com
 package cmd
 
 import (
+	"fmt"
+
+	"github.com/sglyon/jupyteach/internal/model"
 	"github.com/spf13/cobra"
 )
 
+func printReport(report *model.Report) {
+	for _, d := range report.Diagnostics {
+		fmt.Println(d)
+	}
+}
+
 // checkCmd represents the check command
 var checkCmd = &cobra.Command{
 	Use:   "check",
 	Short: "Validate the structure of the directory and content of _course.yml and _lecture.yml files.",
-	Long:  `TODO: long description`,
-	Args:  cobra.MaximumNArgs(1),
+	Long: `Validate the structure of the directory and content of _course.yml and _lecture.yml files.
+
+	Every problem found is reported with the file and line it comes from, and
+	the command exits with a non-zero status if any errors were found. Run this
+	before ` + "`jupyteach push`" + ` to catch mistakes early.`,
+	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		logger.Debug("check called")
 		path, err := cmd.Flags().GetString("path")
 		if err != nil {
 			logger.Fatalf("Must provide a path")
 		}
-		createRepoZip(path)
+
+		report, err := model.CheckCourse(path)
+		if err != nil {
+			logger.Fatal(err)
+		}
+
+		printReport(report)
+
+		if report.HasErrors() {
+			logger.Fatalf("Found %d error(s) and %d warning(s)", report.Errors(), report.Warnings())
+		}
+		logger.Infof("No errors found (%d warning(s))", report.Warnings())
 	},
 }
 
@@ -0,0 +1,155 @@
+/*
+Copyright © 2024 Spencer Lyon spencerlyon2@gmail.com
+*/
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/sglyon/jupyteach/internal/git"
+	"github.com/sglyon/jupyteach/internal/model"
+	"github.com/spf13/cobra"
+)
+
+var (
+	forkDir          string
+	forkName         string
+	forkToStart      string
+	forkSameWeekday  bool
+	forkPush         bool
+	forkStripOutputs bool
+)
+
+// courseCmd represents the course command
+var courseCmd = &cobra.Command{
+	Use:   "course",
+	Short: "Work with the course as a whole",
+	Long: `Work with the course as a whole.
+
+	See the subcommands for what can be done.`,
+}
+
+// courseForkCmd represents the course fork command
+var courseForkCmd = &cobra.Command{
+	Use:   "fork {new_slug}",
+	Short: "Copy the course into a new offering, to push once created in the web app",
+	Long: `Copy the course into a new offering with its own slug.
+
+	The server course of the new offering is not created by this command:
+	create a course with the new slug in the Jupyteach web app, before
+	passing --push or running jupyteach push in the copy.
+
+	The course directory is copied, without its git history, to a directory
+	named after the new slug next to it (or --dir). Every identifier the
+	server assigned (course, lecture, content block, quiz and question ids)
+	is removed from _course.yml, the _lecture.yml files and markdown
+	quizzes, so the copy is pushed as new records and the original course is
+	left untouched. Pass --to-start to move the dates to the new term, as
+	jupyteach dates shift does.
+
+	The copy gets a fresh git repository with a first commit, and the commit
+	sha of the last push is cleared with the other ids. With --push it is then
+	pushed to the course with the new slug.`,
+	Example: `  jupyteach course fork econ-101-spring-2027 --to-start 2027-01-12 --same-weekday`,
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		slug := args[0]
+		path, err := cmd.Flags().GetString("path")
+		if err != nil {
+			logger.Fatalf("Must provide a path")
+		}
+
+		course, err := model.ParseCourseYaml(path)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		if slug == course.Slug {
+			logger.Fatalf("The fork needs a slug other than %s", course.Slug)
+		}
+		dir := forkDir
+		if dir == "" {
+			abs, err := filepath.Abs(path)
+			if err != nil {
+				logger.Fatal(err)
+			}
+			dir = filepath.Join(filepath.Dir(abs), slug)
+		}
+
+		forked, err := model.Fork(path, dir, slug)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		if forkName != "" {
+			forked.Name = forkName
+			if err := forked.WriteYaml(dir); err != nil {
+				cleanupFailure(dir)
+				logger.Fatal(err)
+			}
+		}
+
+		if forkToStart != "" {
+			if err := shiftForkDates(dir, forked); err != nil {
+				cleanupFailure(dir)
+				logger.Fatal(err)
+			}
+		}
+
+		if err := git.Init(dir); err != nil {
+			cleanupFailure(dir)
+			logger.Fatal(err)
+		}
+		if _, err := git.CommitAll(dir, fmt.Sprintf("Fork of %s", course.Slug)); err != nil {
+			cleanupFailure(dir)
+			logger.Fatal(err)
+		}
+		logger.Info("Forked course", "slug", slug, "directory", dir)
+
+		if !forkPush {
+			logger.Infof("Create the course %s in Jupyteach, then run `jupyteach push` in %s", slug, dir)
+			return
+		}
+		pushCourse(dir, slug, forkStripOutputs)
+	},
+}
+
+// shiftForkDates moves the dates of the forked course in dir to --to-start
+func shiftForkDates(dir string, course *model.CourseYaml) error {
+	loc, err := course.Location()
+	if err != nil {
+		return err
+	}
+	start, err := model.ParseTime(forkToStart, loc)
+	if err != nil {
+		return err
+	}
+	shift, err := course.ShiftDates(dir, start, forkSameWeekday)
+	if err != nil {
+		return err
+	}
+	if err := shift.Course.WriteYaml(dir); err != nil {
+		return err
+	}
+	for lectureDir, lecture := range shift.Lectures {
+		if err := lecture.WriteYaml(filepath.Join(dir, lectureDir, "_lecture.yml")); err != nil {
+			return err
+		}
+	}
+	for _, c := range shift.Relative {
+		logger.Warn("Left a time counted from today as is", "file", c.File, "field", c.Field, "value", c.Old)
+	}
+	logger.Info("Shifted course dates", "days", shift.Days, "changes", len(shift.Changes))
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(courseCmd)
+	courseCmd.AddCommand(courseForkCmd)
+
+	courseForkCmd.Flags().StringVar(&forkDir, "dir", "", "directory of the new course (default the new slug, next to the course)")
+	courseForkCmd.Flags().StringVar(&forkName, "name", "", "name of the new course (default the name of the course)")
+	courseForkCmd.Flags().StringVar(&forkToStart, "to-start", "", "move the course dates so the new course starts on this day, e.g. 2027-01-12")
+	courseForkCmd.Flags().BoolVar(&forkSameWeekday, "same-weekday", false, "with --to-start, move dates by whole weeks so they keep their weekday")
+	courseForkCmd.Flags().BoolVar(&forkPush, "push", false, "push the copy to the course with the new slug, already created in the web app")
+	courseForkCmd.Flags().BoolVar(&forkStripOutputs, "strip-outputs", false, "with --push, remove outputs and execution counts from notebooks sent to the server")
+}
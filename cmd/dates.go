@@ -0,0 +1,136 @@
+/*
+Copyright © 2024 Spencer Lyon spencerlyon2@gmail.com
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/sglyon/jupyteach/internal/model"
+	"github.com/spf13/cobra"
+)
+
+var (
+	datesToStart     string
+	datesSameWeekday bool
+	datesYes         bool
+	datesDryRun      bool
+)
+
+// datesCmd represents the dates command
+var datesCmd = &cobra.Command{
+	Use:   "dates",
+	Short: "Work with the dates of the course",
+	Long: `Work with the dates of the course.
+
+	See the subcommands for what can be done.`,
+}
+
+// datesShiftCmd represents the dates shift command
+var datesShiftCmd = &cobra.Command{
+	Use:   "shift",
+	Short: "Move every course date to a new term",
+	Long: `Move every course date to a new term.
+
+	The start_date of _course.yml moves to --to-start, and the end_date, the
+	available_at of every lecture and the cli_due_at of every block in the
+	_lecture.yml files move by the same number of days. Times keep their time
+	of day in the course timezone and the format they are written in.
+
+	With --same-weekday, dates move by whole weeks instead, the fewest that
+	don't bring them earlier relative to the new start, so lectures stay on
+	the days the class meets. Expressions counted from start or end, like
+	start+2w, follow the new course dates by themselves.
+
+	The changes are shown, and files are only updated after you confirm them
+	(or with --yes).`,
+	Example: `  jupyteach dates shift --to-start 2027-01-12 --same-weekday --dry-run`,
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		path, err := cmd.Flags().GetString("path")
+		if err != nil {
+			logger.Fatalf("Must provide a path")
+		}
+
+		course, err := model.ParseCourseYaml(path)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		loc, err := course.Location()
+		if err != nil {
+			logger.Fatal(err)
+		}
+		start, err := model.ParseTime(datesToStart, loc)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		shift, err := course.ShiftDates(path, start, datesSameWeekday)
+		if err != nil {
+			logger.Fatal(err)
+		}
+
+		fmt.Printf("Moving dates by %s (%s to %s)\n\n", describeDays(shift.Days), course.StartDate, shift.Course.StartDate)
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "FILE\tFIELD\tOLD\tNEW")
+		for _, c := range shift.Changes {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", c.File, c.Field, c.Old, c.New)
+		}
+		w.Flush()
+		for _, c := range shift.Relative {
+			logger.Warn("Left a time counted from today as is", "file", c.File, "field", c.Field, "value", c.Old)
+		}
+
+		if datesDryRun || len(shift.Changes) == 0 {
+			return
+		}
+		if !datesYes {
+			ok, err := confirm("Write these dates?")
+			if err != nil {
+				logger.Fatal(err)
+			}
+			if !ok {
+				return
+			}
+		}
+
+		if err := shift.Course.WriteYaml(path); err != nil {
+			logger.Fatal(err)
+		}
+		for dir, lecture := range shift.Lectures {
+			if err := lecture.WriteYaml(filepath.Join(path, dir, "_lecture.yml")); err != nil {
+				logger.Fatal(err)
+			}
+		}
+		logger.Info("Shifted course dates", "changes", len(shift.Changes))
+	},
+}
+
+// describeDays formats a number of days, adding the weeks when it is a
+// whole number of them
+func describeDays(days int) string {
+	plural := func(n int, word string) string {
+		if n == 1 || n == -1 {
+			return fmt.Sprintf("%d %s", n, word)
+		}
+		return fmt.Sprintf("%d %ss", n, word)
+	}
+	s := plural(days, "day")
+	if days != 0 && days%7 == 0 {
+		s += ", " + plural(days/7, "week")
+	}
+	return s
+}
+
+func init() {
+	rootCmd.AddCommand(datesCmd)
+	datesCmd.AddCommand(datesShiftCmd)
+
+	datesShiftCmd.Flags().StringVar(&datesToStart, "to-start", "", "new start_date of the course, e.g. 2027-01-12")
+	datesShiftCmd.Flags().BoolVar(&datesSameWeekday, "same-weekday", false, "move dates by whole weeks so they keep their weekday")
+	datesShiftCmd.Flags().BoolVarP(&datesYes, "yes", "y", false, "write the new dates without asking")
+	datesShiftCmd.Flags().BoolVar(&datesDryRun, "dry-run", false, "only show the changes")
+	datesShiftCmd.MarkFlagRequired("to-start")
+}
@@ -0,0 +1,167 @@
+/*
+Copyright © 2024 Spencer Lyon spencerlyon2@gmail.com
+*/
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/sglyon/jupyteach/internal/imscc"
+	"github.com/sglyon/jupyteach/internal/model"
+	"github.com/sglyon/jupyteach/internal/scorm"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportOut          string
+	exportForce        bool
+	exportStripOutputs bool
+)
+
+// exportOutPath returns where an export of course should be written, refusing
+// to overwrite an existing file unless --force was given
+func exportOutPath(path string, course *model.CourseYaml, ext string) (string, error) {
+	return outPath(exportOut, exportForce, path, course, ext)
+}
+
+// outPath returns out, or <course slug><ext> next to the course directory
+// when out is empty, failing if the file exists and force is false. The
+// default is kept out of the course directory since a file left in the repo
+// stops push.
+func outPath(out string, force bool, path string, course *model.CourseYaml, ext string) (string, error) {
+	if out == "" {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return "", err
+		}
+		out = filepath.Join(filepath.Dir(abs), course.Slug+ext)
+	}
+	if _, err := os.Stat(out); err == nil && !force {
+		return "", fmt.Errorf("%s already exists. Use --force to overwrite it", out)
+	}
+	return out, nil
+}
+
+// writeExport creates the file out and fills it with write
+func writeExport(out string, write func(io.Writer) error) error {
+	f, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		os.Remove(out)
+		return err
+	}
+	return f.Close()
+}
+
+// exportCmd represents the export command
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the course to packages other systems can import",
+	Long: `Export the course to packages other systems can import.
+
+	See the subcommands for the supported formats.`,
+}
+
+// exportImsccCmd represents the export imscc command
+var exportImsccCmd = &cobra.Command{
+	Use:   "imscc",
+	Short: "Export the course as an IMS Common Cartridge",
+	Long: `Export the course as an IMS Common Cartridge 1.3 package.
+
+	The cartridge can be imported into Canvas, Moodle, Blackboard and other
+	learning management systems. Every lecture becomes a module, in the order
+	of _course.yml, holding its notebooks, markdown pages (rendered to HTML),
+	links, videos and quizzes. The syllabus, when present, is added as its
+	own module. The course source files are included as well so the
+	cartridge doubles as a portable copy of the course.
+
+	The package is written to <course slug>.imscc next to the course
+	directory unless --out is given.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		path, err := cmd.Flags().GetString("path")
+		if err != nil {
+			logger.Fatalf("Must provide a path")
+		}
+
+		course, err := model.ParseCourseYaml(path)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		out, err := exportOutPath(path, course, ".imscc")
+		if err != nil {
+			logger.Fatal(err)
+		}
+
+		cartridge, err := imscc.Build(path, course, model.ZipOptions{StripOutputs: exportStripOutputs})
+		if err != nil {
+			logger.Fatal(err)
+		}
+		for _, s := range cartridge.Skipped {
+			logger.Warn("Skipped content block", "block", s)
+		}
+		if err := writeExport(out, cartridge.WriteZip); err != nil {
+			logger.Fatal(err)
+		}
+		logger.Info("Exported course as a Common Cartridge", "file", out)
+	},
+}
+
+// exportScormCmd represents the export scorm command
+var exportScormCmd = &cobra.Command{
+	Use:   "scorm",
+	Short: "Export the course as a SCORM 1.2 package",
+	Long: `Export the course as a SCORM 1.2 package.
+
+	Every markdown, notebook, video and link block is rendered to an HTML page
+	that the LMS launches as its own SCO and that is marked completed once
+	viewed. Lectures group their pages in the order of _course.yml. Quizzes
+	are not part of the package.
+
+	The package is written to <course slug>-scorm.zip next to the course
+	directory unless --out is given.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		path, err := cmd.Flags().GetString("path")
+		if err != nil {
+			logger.Fatalf("Must provide a path")
+		}
+
+		course, err := model.ParseCourseYaml(path)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		out, err := exportOutPath(path, course, "-scorm.zip")
+		if err != nil {
+			logger.Fatal(err)
+		}
+
+		pkg, err := scorm.Build(path, course, model.ZipOptions{StripOutputs: exportStripOutputs})
+		if err != nil {
+			logger.Fatal(err)
+		}
+		for _, s := range pkg.Skipped {
+			logger.Warn("Skipped content block", "block", s)
+		}
+		if err := writeExport(out, pkg.WriteZip); err != nil {
+			logger.Fatal(err)
+		}
+		logger.Info("Exported course as a SCORM package", "file", out)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.AddCommand(exportImsccCmd)
+	exportCmd.AddCommand(exportScormCmd)
+
+	exportCmd.PersistentFlags().StringVarP(&exportOut, "out", "o", "", "file to write the package to")
+	exportCmd.PersistentFlags().BoolVar(&exportForce, "force", false, "overwrite an existing package")
+	exportCmd.PersistentFlags().BoolVar(&exportStripOutputs, "strip-outputs", false, "remove outputs from notebooks in the package")
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sglyon/jupyteach/internal/model"
+)
+
+func TestOutPath(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "course")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	course := &model.CourseYaml{Slug: "demo"}
+
+	// the default stays out of the course repo so push still works
+	out, err := outPath("", false, dir, course, ".imscc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(filepath.Dir(dir), "demo.imscc"); out != want {
+		t.Errorf("default out = %s, want %s", out, want)
+	}
+
+	if err := os.WriteFile(out, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := outPath("", false, dir, course, ".imscc"); err == nil {
+		t.Error("expected an error for an existing file without force")
+	}
+	if _, err := outPath("", true, dir, course, ".imscc"); err != nil {
+		t.Error(err)
+	}
+	if got, _ := outPath("x.zip", false, dir, course, ".imscc"); got != "x.zip" {
+		t.Errorf("out = %s, want x.zip", got)
+	}
+}
@@ -0,0 +1,105 @@
+/*
+Copyright © 2024 Spencer Lyon spencerlyon2@gmail.com
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/sglyon/jupyteach/internal/git"
+	"github.com/sglyon/jupyteach/internal/model"
+	"github.com/spf13/cobra"
+)
+
+var (
+	fixApply         bool
+	fixForceReformat bool
+)
+
+// fixCmd represents the fix command
+var fixCmd = &cobra.Command{
+	Use:   "fix",
+	Short: "Repair course metadata problems that check finds",
+	Long: `Repair the problems jupyteach check finds that have only one sensible fix.
+
+	- lecture directories whose name doesn't match the lecture title are moved
+	  with git mv
+	- lectures in _course.yml whose directory is gone are pointed at the
+	  directory holding the lecture (found by lecture_id or title), or removed
+	- lecture directories missing from _course.yml are added after the other
+	  lectures
+	- content blocks whose file was moved within the lecture are pointed at it,
+	  when only one file in the lecture has its name
+	- _course.yml and _lecture.yml files are rewritten the way jupyteach writes
+	  them, unless they have comments or fields jupyteach doesn't read
+
+	Repairs that need a file with comments or unknown fields rewritten are
+	left for you to make by hand, since the rewrite loses them, unless
+	--force-reformat is given.
+
+	The repairs are listed, with the problems that need a person to fix them,
+	and only made with --apply.`,
+	Example: `  jupyteach fix
+  jupyteach fix --apply`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		path, err := cmd.Flags().GetString("path")
+		if err != nil {
+			logger.Fatalf("Must provide a path")
+		}
+
+		plan, err := model.PlanFixes(path, model.FixOptions{ForceReformat: fixForceReformat})
+		if err != nil {
+			logger.Fatal(err)
+		}
+		if len(plan.Repairs) == 0 && len(plan.Skipped) == 0 {
+			logger.Info("Nothing to fix")
+			return
+		}
+		printRepairs(plan.Repairs)
+		if len(plan.Skipped) > 0 {
+			if len(plan.Repairs) > 0 {
+				fmt.Println()
+			}
+			fmt.Println("Needs fixing by hand:")
+			printRepairs(plan.Skipped)
+		}
+		if len(plan.Repairs) == 0 {
+			return
+		}
+		if !fixApply {
+			fmt.Println("\nRun jupyteach fix --apply to make these repairs.")
+			return
+		}
+
+		err = plan.Apply(path, func(from, to string) error {
+			return git.Move(path, from, to)
+		})
+		if err != nil {
+			logger.Fatal(err)
+		}
+		logger.Info("Applied repairs", "count", len(plan.Repairs))
+	},
+}
+
+// printRepairs writes repairs to stdout as a table
+func printRepairs(repairs []model.Repair) {
+	if len(repairs) == 0 {
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "FILE\tPROBLEM\tFIX")
+	for _, r := range repairs {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", r.File, r.Problem, r.Fix)
+	}
+	w.Flush()
+}
+
+func init() {
+	rootCmd.AddCommand(fixCmd)
+
+	fixCmd.Flags().BoolVar(&fixApply, "apply", false, "make the repairs instead of only listing them")
+	fixCmd.Flags().BoolVar(&fixForceReformat, "force-reformat", false, "also make repairs that rewrite files with comments or unknown fields, losing them")
+}
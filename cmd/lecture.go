@@ -0,0 +1,159 @@
+/*
+Copyright © 2024 Spencer Lyon spencerlyon2@gmail.com
+*/
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/sglyon/jupyteach/internal/git"
+	"github.com/sglyon/jupyteach/internal/model"
+	"github.com/spf13/cobra"
+)
+
+var (
+	lectureMoveBefore string
+	lectureMoveAfter  string
+)
+
+// lectureCmd represents the lecture command
+var lectureCmd = &cobra.Command{
+	Use:   "lecture",
+	Short: "Work with the lectures of the course",
+	Long: `Work with the lectures of the course.
+
+	See the subcommands for what can be done.`,
+}
+
+// lectureRenameCmd represents the lecture rename command
+var lectureRenameCmd = &cobra.Command{
+	Use:   "rename {directory} [title]",
+	Short: "Give a lecture a new title and directory",
+	Long: `Give a lecture a new title and move it to the directory named after it.
+
+	The title in the lecture's _lecture.yml is updated, the directory is
+	moved with git mv so its history follows, and the directory in
+	_course.yml is updated. The lecture keeps its ids, so the server sees the
+	same lecture under a new name on the next push.
+
+	Without a title the current one is kept and only the directory is
+	renamed, which brings directory names made with older naming rules up to
+	date.`,
+	Example: `  jupyteach lecture rename intro "Introduction to Python"`,
+	Args:    cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		path, err := cmd.Flags().GetString("path")
+		if err != nil {
+			logger.Fatalf("Must provide a path")
+		}
+		title := ""
+		if len(args) == 2 {
+			title = args[1]
+		}
+
+		course, err := model.ParseCourseYaml(path)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		newDir, err := course.RenameLecture(path, args[0], title, func(from, to string) error {
+			return git.Move(path, from, to)
+		})
+		if err != nil {
+			logger.Fatal(err)
+		}
+		logger.Info("Renamed lecture", "directory", newDir)
+	},
+}
+
+// lectureMoveCmd represents the lecture move command
+var lectureMoveCmd = &cobra.Command{
+	Use:   "move {directory} --before|--after {directory}",
+	Short: "Move a lecture to another place in the course",
+	Long: `Move a lecture to just before or just after another lecture.
+
+	Lectures are shown in the order of the lectures list in _course.yml, which
+	is rewritten. Run jupyteach lecture reorder to move lectures around
+	interactively.`,
+	Example: `  jupyteach lecture move week-3 --before week-2`,
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		path, err := cmd.Flags().GetString("path")
+		if err != nil {
+			logger.Fatalf("Must provide a path")
+		}
+		course, err := model.ParseCourseYaml(path)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		target, after := moveTarget(lectureMoveBefore, lectureMoveAfter)
+		if err := course.MoveLecture(args[0], target, after); err != nil {
+			logger.Fatal(err)
+		}
+		if err := course.WriteYaml(path); err != nil {
+			logger.Fatal(err)
+		}
+		logger.Info("Moved lecture", "directory", args[0])
+	},
+}
+
+// lectureReorderCmd represents the lecture reorder command
+var lectureReorderCmd = &cobra.Command{
+	Use:   "reorder",
+	Short: "Put the lectures in a new order interactively",
+	Long: `Put the lectures in a new order interactively.
+
+	Pick a lecture with the arrow keys, grab it with space and move it with
+	the arrow keys, or move it at once with shift and the arrow keys. Enter
+	saves the new order to _course.yml and q quits without saving.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		path, err := cmd.Flags().GetString("path")
+		if err != nil {
+			logger.Fatalf("Must provide a path")
+		}
+		course, err := model.ParseCourseYaml(path)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		items := make([]string, len(course.Lectures))
+		for i, cl := range course.Lectures {
+			items[i] = fmt.Sprintf("%s (%s)", lectureTitle(path, cl.Directory), cl.Directory)
+		}
+
+		order, save, err := runReorder("Lectures of "+course.Slug, items)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		if !save {
+			return
+		}
+		if err := course.ReorderLectures(order); err != nil {
+			logger.Fatal(err)
+		}
+		if err := course.WriteYaml(path); err != nil {
+			logger.Fatal(err)
+		}
+		logger.Info("Saved the lecture order")
+	},
+}
+
+// moveTarget returns what to move next to from the --before and --after
+// flags, and whether it is after
+func moveTarget(before, after string) (string, bool) {
+	if after != "" {
+		return after, true
+	}
+	return before, false
+}
+
+func init() {
+	rootCmd.AddCommand(lectureCmd)
+	lectureCmd.AddCommand(lectureRenameCmd)
+	lectureCmd.AddCommand(lectureMoveCmd)
+	lectureCmd.AddCommand(lectureReorderCmd)
+
+	lectureMoveCmd.Flags().StringVar(&lectureMoveBefore, "before", "", "directory of the lecture to move before")
+	lectureMoveCmd.Flags().StringVar(&lectureMoveAfter, "after", "", "directory of the lecture to move after")
+	lectureMoveCmd.MarkFlagsMutuallyExclusive("before", "after")
+	lectureMoveCmd.MarkFlagsOneRequired("before", "after")
+}
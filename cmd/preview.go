@@ -0,0 +1,106 @@
+/*
+Copyright © 2024 Spencer Lyon spencerlyon2@gmail.com
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+
+	"github.com/sglyon/jupyteach/internal/site"
+	"github.com/spf13/cobra"
+)
+
+var (
+	previewHost         string
+	previewPort         int
+	previewNoReload     bool
+	previewStripOutputs bool
+	previewAsOf         string
+)
+
+// previewCmd represents the preview command
+var previewCmd = &cobra.Command{
+	Use:   "preview",
+	Short: "Preview the course in a browser",
+	Long: `Start a local web server that shows the course as students would see it.
+
+	The course page lists the lectures in the order of _course.yml. Each
+	lecture page shows its content blocks: markdown rendered to HTML,
+	notebooks rendered read only, embedded videos, links and quizzes as forms.
+
+	Pages reload automatically when a file of the course changes, unless
+	--no-reload is given.
+
+	Pass --as-of to see the course as students will at a given time: only the
+	lectures available by then are shown.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		path, err := cmd.Flags().GetString("path")
+		if err != nil {
+			logger.Fatalf("Must provide a path")
+		}
+		if _, err := os.Stat(path + "/_course.yml"); err != nil {
+			logger.Fatal("_course.yml does not exist. Run preview from the course directory or pass --path")
+		}
+
+		s := &site.Site{
+			Dir:          path,
+			LiveReload:   !previewNoReload,
+			StripOutputs: previewStripOutputs,
+		}
+		if previewAsOf != "" {
+			if s.AsOf, err = parseAsOf(path, previewAsOf); err != nil {
+				logger.Fatal(err)
+			}
+		}
+		server := site.NewServer(s)
+		server.OnError = func(page string, err error) {
+			logger.Error("Failed to render page", "page", page, "err", err)
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		if !previewNoReload {
+			go func() {
+				err := server.Watch(ctx, func(name string) {
+					logger.Debug("File changed", "file", name)
+				})
+				if err != nil {
+					logger.Error("Stopped watching for changes", "err", err)
+				}
+			}()
+		}
+
+		listener, err := net.Listen("tcp", net.JoinHostPort(previewHost, fmt.Sprint(previewPort)))
+		if err != nil {
+			logger.Fatal(err)
+		}
+		httpServer := &http.Server{Handler: server}
+		go func() {
+			<-ctx.Done()
+			httpServer.Close()
+		}()
+
+		logger.Infof("Previewing course at http://%s", listener.Addr())
+		if err := httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(previewCmd)
+
+	previewCmd.Flags().StringVar(&previewHost, "host", "127.0.0.1", "address to listen on")
+	previewCmd.Flags().IntVarP(&previewPort, "port", "p", 8000, "port to listen on")
+	previewCmd.Flags().BoolVar(&previewNoReload, "no-reload", false, "don't reload pages when files change")
+	previewCmd.Flags().StringVar(&previewAsOf, "as-of", "", "only show lectures visible at this time, e.g. \"2024-09-02 08:00\" or now")
+	previewCmd.Flags().BoolVar(&previewStripOutputs, "strip-outputs", false, "hide notebook outputs")
+}
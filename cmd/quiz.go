@@ -0,0 +1,170 @@
+/*
+Copyright © 2024 Spencer Lyon spencerlyon2@gmail.com
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sglyon/jupyteach/internal/model"
+	"github.com/sglyon/jupyteach/internal/runner"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	quizTestPython  string
+	quizTestRunner  string
+	quizTestTimeout time.Duration
+	quizTestVerbose bool
+)
+
+// selectLectureDirectories returns the lecture directories (relative to path)
+// a command should operate on. An explicit argument wins, then the current
+// directory if it holds a `_lecture.yml`, and finally every lecture in the
+// course.
+func selectLectureDirectories(path string, args []string) ([]string, error) {
+	if len(args) > 0 {
+		return []string{strings.TrimSuffix(args[0], string(filepath.Separator))}, nil
+	}
+	if _, err := os.Stat(filepath.Join(path, "_lecture.yml")); err == nil {
+		return []string{"."}, nil
+	}
+	course, err := model.ParseCourseYaml(path)
+	if err != nil {
+		return nil, err
+	}
+	dirs := make([]string, len(course.Lectures))
+	for i, cl := range course.Lectures {
+		dirs[i] = cl.Directory
+	}
+	return dirs, nil
+}
+
+func newQuizRunner() (runner.Runner, error) {
+	if quizTestRunner != "" {
+		return runner.NewCommandRunner(quizTestRunner, quizTestTimeout)
+	}
+	python := quizTestPython
+	if python == "" {
+		python = viper.GetString("PYTHON")
+	}
+	return runner.NewPythonRunner(python, quizTestTimeout), nil
+}
+
+func printRunOutput(output string) {
+	for _, line := range strings.Split(strings.TrimRight(output, "\n"), "\n") {
+		fmt.Printf("      | %s\n", line)
+	}
+}
+
+// quizCmd represents the quiz command
+var quizCmd = &cobra.Command{
+	Use:   "quiz",
+	Short: "Work with the quizzes in your jupyteach course",
+}
+
+// quizTestCmd represents the quiz test command
+var quizTestCmd = &cobra.Command{
+	Use:   "test [lecture]",
+	Short: "Run the autograder tests of code questions locally",
+	Long: `Run the autograder tests of every code question locally.
+
+	For each question the quiz start_code, setup_code, solution and test_code
+	are run together and must succeed. If the question has starting_code, the
+	same program with starting_code in place of the solution must fail, so
+	students can't pass without doing any work.
+
+	Programs run with the python interpreter from --python (or the PYTHON
+	config value, default python3). Use --runner to run them with any other
+	command instead; the program file is passed as the last argument.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		path, err := cmd.Flags().GetString("path")
+		if err != nil {
+			logger.Fatalf("Must provide a path")
+		}
+
+		r, err := newQuizRunner()
+		if err != nil {
+			logger.Fatal(err)
+		}
+
+		dirs, err := selectLectureDirectories(path, args)
+		if err != nil {
+			logger.Fatal(err)
+		}
+
+		var passed, failed int
+		for _, dir := range dirs {
+			lectureDir, err := filepath.Abs(filepath.Join(path, dir))
+			if err != nil {
+				logger.Fatal(err)
+			}
+			lecture, err := model.ParseLectureYaml(filepath.Join(lectureDir, "_lecture.yml"))
+			if err != nil {
+				logger.Fatal(err)
+			}
+
+			for _, cb := range lecture.ContentBlocks {
+				if cb.Type != "quiz" {
+					continue
+				}
+				quiz, err := cb.LoadQuiz(lectureDir)
+				if err != nil {
+					logger.Fatal(err)
+				}
+				results, err := runner.TestQuiz(context.Background(), r, lectureDir, quiz)
+				if err != nil {
+					logger.Fatal(err)
+				}
+				if len(results) == 0 {
+					continue
+				}
+				fmt.Printf("%s: %s\n", dir, cb.Title)
+
+				for _, res := range results {
+					status := "PASS"
+					if !res.Passed() {
+						status = "FAIL"
+						failed++
+					} else {
+						passed++
+					}
+					fmt.Printf("  %s question %d\n", status, res.Index+1)
+					if !res.Solution.Passed {
+						fmt.Println("    solution does not pass test_code:")
+						printRunOutput(res.Solution.Output)
+					} else if quizTestVerbose && res.Solution.Output != "" {
+						printRunOutput(res.Solution.Output)
+					}
+					if res.Starting != nil && res.Starting.Passed {
+						fmt.Println("    starting_code passes test_code without any changes")
+					}
+				}
+			}
+		}
+
+		if failed > 0 {
+			logger.Fatalf("%d of %d code question(s) failed", failed, failed+passed)
+		}
+		logger.Infof("All %d code question(s) passed", passed)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(quizCmd)
+	quizCmd.AddCommand(quizTestCmd)
+
+	viper.SetDefault("PYTHON", "python3")
+
+	quizTestCmd.Flags().StringVar(&quizTestPython, "python", "", "python interpreter used to run the tests (default from PYTHON config, python3)")
+	quizTestCmd.Flags().StringVar(&quizTestRunner, "runner", "", "command used to run the tests instead of python, the program file is appended")
+	quizTestCmd.Flags().DurationVar(&quizTestTimeout, "timeout", 30*time.Second, "maximum time for each program")
+	quizTestCmd.Flags().BoolVarP(&quizTestVerbose, "verbose", "v", false, "show output of passing programs")
+}
@@ -0,0 +1,185 @@
+/*
+Copyright © 2024 Spencer Lyon spencerlyon2@gmail.com
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/sglyon/jupyteach/internal/model"
+	"github.com/sglyon/jupyteach/internal/qti"
+	"github.com/spf13/cobra"
+)
+
+var (
+	quizExportFormat   string
+	quizExportLanguage string
+	quizExportMigrate  bool
+	quizExportForce    bool
+	quizExportOut      string
+)
+
+// quizMarkdownFilename returns the name of the markdown file for the n-th
+// (zero based) quiz exported from a lecture
+func quizMarkdownFilename(n int) string {
+	if n == 0 {
+		return "quiz.md"
+	}
+	return fmt.Sprintf("quiz-%d.md", n+1)
+}
+
+func exportQuizzesMarkdown(path string, dirs []string) error {
+	for _, dir := range dirs {
+		lectureDir := filepath.Join(path, dir)
+		lecturePath := filepath.Join(lectureDir, "_lecture.yml")
+		lecture, err := model.ParseLectureYaml(lecturePath)
+		if err != nil {
+			return err
+		}
+
+		exported := 0
+		for i, cb := range lecture.ContentBlocks {
+			if cb.Type != "quiz" || model.IsQuizMarkdown(cb.Filename) {
+				continue
+			}
+			filename := quizMarkdownFilename(exported)
+			exported++
+
+			out := filepath.Join(lectureDir, filename)
+			if _, err := os.Stat(out); err == nil && !quizExportForce {
+				return fmt.Errorf("%s already exists. Use --force to overwrite it", out)
+			}
+
+			content, err := model.FormatQuizMarkdown(cb.Quiz, quizExportLanguage)
+			if err != nil {
+				return err
+			}
+			if err := os.WriteFile(out, content, 0o644); err != nil {
+				return err
+			}
+			logger.Info("Exported quiz", "quiz", cb.Title, "file", out)
+
+			if quizExportMigrate {
+				lecture.ContentBlocks[i].Filename = filename
+				lecture.ContentBlocks[i].Quiz.Questions = []model.Question{}
+			}
+		}
+
+		if quizExportMigrate && exported > 0 {
+			if err := writeYaml(lecturePath, lecture); err != nil {
+				return err
+			}
+			logger.Info("Updated lecture to use markdown quizzes", "file", lecturePath)
+		}
+	}
+	return nil
+}
+
+func exportQuizzesQTI(path string, dirs []string) error {
+	course, err := model.ParseCourseYaml(path)
+	if err != nil {
+		return err
+	}
+	out, err := outPath(quizExportOut, quizExportForce, path, course, "-qti.zip")
+	if err != nil {
+		return err
+	}
+
+	pkg := qti.NewPackage(course.Slug)
+	for _, dir := range dirs {
+		lectureDir := filepath.Join(path, dir)
+		lecture, err := model.ParseLectureYaml(filepath.Join(lectureDir, "_lecture.yml"))
+		if err != nil {
+			return err
+		}
+
+		n := 0
+		for _, cb := range lecture.ContentBlocks {
+			if cb.Type != "quiz" {
+				continue
+			}
+			n++
+			quiz, err := cb.LoadQuiz(lectureDir)
+			if err != nil {
+				return err
+			}
+			title := cb.Title
+			if title == "" {
+				title = fmt.Sprintf("%s quiz %d", lecture.Title, n)
+			}
+			if err := pkg.AddQuiz(fmt.Sprintf("%s-quiz-%d", filepath.Base(lectureDir), n), title, quiz); err != nil {
+				return fmt.Errorf("%s: %w", lectureDir, err)
+			}
+		}
+	}
+	if pkg.Tests() == 0 {
+		return fmt.Errorf("no quizzes found")
+	}
+
+	f, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := pkg.WriteZip(f); err != nil {
+		return err
+	}
+	logger.Info("Exported quizzes as a QTI 2.1 package", "quizzes", pkg.Tests(), "file", out)
+	return nil
+}
+
+// quizExportCmd represents the quiz export command
+var quizExportCmd = &cobra.Command{
+	Use:   "export [lecture]",
+	Short: "Export quizzes to other formats",
+	Long: `Export quizzes to other formats.
+
+	With --format markdown (the default) every quiz written inline in
+	_lecture.yml is converted to the markdown quiz format and saved next to
+	the lecture as quiz.md (quiz-2.md, ... for further quizzes). Pass
+	--migrate to also point the quiz content blocks at the new files, after
+	which the markdown files are the source of truth for the questions.
+
+	With --format qti every quiz is written to a single IMS QTI 2.1 package
+	(<course slug>-qti.zip next to the course directory by default, see
+	--out) that can be imported into other learning management systems.
+	Each quiz becomes an assessment test.
+	Code, fill in the blank and freeform questions are exported as extended
+	text items that start from the question's starting code.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		path, err := cmd.Flags().GetString("path")
+		if err != nil {
+			logger.Fatalf("Must provide a path")
+		}
+
+		dirs, err := selectLectureDirectories(path, args)
+		if err != nil {
+			logger.Fatal(err)
+		}
+
+		switch quizExportFormat {
+		case "markdown", "md":
+			err = exportQuizzesMarkdown(path, dirs)
+		case "qti":
+			err = exportQuizzesQTI(path, dirs)
+		default:
+			err = fmt.Errorf("unknown format %s", quizExportFormat)
+		}
+		if err != nil {
+			logger.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	quizCmd.AddCommand(quizExportCmd)
+
+	quizExportCmd.Flags().StringVarP(&quizExportFormat, "format", "f", "markdown", "export format: markdown or qti")
+	quizExportCmd.Flags().StringVar(&quizExportLanguage, "language", "python", "language used to label code blocks")
+	quizExportCmd.Flags().BoolVar(&quizExportMigrate, "migrate", false, "point the quiz content blocks at the exported markdown files")
+	quizExportCmd.Flags().StringVarP(&quizExportOut, "out", "o", "", "file to write the qti package to")
+	quizExportCmd.Flags().BoolVar(&quizExportForce, "force", false, "overwrite existing files")
+}
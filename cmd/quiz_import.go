@@ -0,0 +1,126 @@
+/*
+Copyright © 2024 Spencer Lyon spencerlyon2@gmail.com
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sglyon/jupyteach/internal/model"
+	"github.com/sglyon/jupyteach/internal/moodle"
+	"github.com/spf13/cobra"
+)
+
+var (
+	quizImportFormat  string
+	quizImportLecture string
+	quizImportTitle   string
+)
+
+func importQuestions(format, file string) (moodle.Result, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return moodle.Result{}, err
+	}
+
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(file)) {
+		case ".xml":
+			format = "moodle-xml"
+		case ".gift", ".txt":
+			format = "gift"
+		default:
+			return moodle.Result{}, fmt.Errorf("unable to tell the format of %s, pass --format gift or --format moodle-xml", file)
+		}
+	}
+
+	switch format {
+	case "gift":
+		return moodle.ParseGIFT(data), nil
+	case "moodle-xml", "xml":
+		return moodle.ParseMoodleXML(data)
+	}
+	return moodle.Result{}, fmt.Errorf("unknown format %s, must be gift or moodle-xml", format)
+}
+
+// quizImportCmd represents the quiz import command
+var quizImportCmd = &cobra.Command{
+	Use:   "import {file}",
+	Short: "Import a quiz from a Moodle GIFT or Moodle XML file",
+	Long: `Import a quiz from a Moodle GIFT or Moodle XML file.
+
+	Multiple choice, multiple answer, true/false, short answer and essay
+	questions are converted and added as a new quiz content block at the end of
+	the lecture. Questions that can't be converted are listed so they can be
+	added by hand.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		path, err := cmd.Flags().GetString("path")
+		if err != nil {
+			logger.Fatalf("Must provide a path")
+		}
+
+		var lectureArgs []string
+		if quizImportLecture != "" {
+			lectureArgs = []string{quizImportLecture}
+		}
+		dirs, err := selectLectureDirectories(path, lectureArgs)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		if len(dirs) != 1 {
+			logger.Fatal("Must choose the lecture to import into with --lecture")
+		}
+		lecturePath := filepath.Join(path, dirs[0], "_lecture.yml")
+		lecture, err := model.ParseLectureYaml(lecturePath)
+		if err != nil {
+			logger.Fatal(err)
+		}
+
+		result, err := importQuestions(quizImportFormat, args[0])
+		if err != nil {
+			logger.Fatal(err)
+		}
+		for _, s := range result.Skipped {
+			logger.Warn("Skipped question", "question", s.Name, "reason", s.Reason)
+		}
+		if len(result.Questions) == 0 {
+			logger.Fatal("No questions could be converted")
+		}
+		for i, q := range result.Questions {
+			for _, issue := range q.Validate() {
+				logger.Warn("Imported question needs attention", "question", i+1, "problem", issue)
+			}
+		}
+
+		title := quizImportTitle
+		if title == "" {
+			base := filepath.Base(args[0])
+			title = strings.TrimSuffix(base, filepath.Ext(base))
+		}
+		lecture.ContentBlocks = append(lecture.ContentBlocks, model.ContentBlockYaml{
+			Type:  "quiz",
+			Title: title,
+			Quiz:  model.Quiz{Questions: result.Questions},
+		})
+		if err := writeYaml(lecturePath, lecture); err != nil {
+			logger.Fatal(err)
+		}
+
+		logger.Infof(
+			"Imported %d question(s) into %s, skipped %d",
+			len(result.Questions), lecturePath, len(result.Skipped),
+		)
+	},
+}
+
+func init() {
+	quizCmd.AddCommand(quizImportCmd)
+
+	quizImportCmd.Flags().StringVarP(&quizImportFormat, "format", "f", "", "format of the file: gift or moodle-xml (default from the file extension)")
+	quizImportCmd.Flags().StringVarP(&quizImportLecture, "lecture", "l", "", "lecture directory to add the quiz to")
+	quizImportCmd.Flags().StringVarP(&quizImportTitle, "title", "t", "", "title of the quiz (default from the file name)")
+}
@@ -0,0 +1,140 @@
+/*
+Copyright © 2024 Spencer Lyon spencerlyon2@gmail.com
+*/
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	reorderTitleStyle   = lipgloss.NewStyle().Bold(true).MarginBottom(1)
+	reorderCursorStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("212"))
+	reorderGrabbedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("212")).Bold(true)
+	reorderHelpStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("241")).MarginTop(1)
+)
+
+// reorderModel is a list whose items can be moved around with the keyboard.
+// order holds the original position of each item as shown.
+type reorderModel struct {
+	title   string
+	items   []string
+	order   []int
+	cursor  int
+	grabbed bool
+	saved   bool
+}
+
+func newReorderModel(title string, items []string) reorderModel {
+	order := make([]int, len(items))
+	for i := range order {
+		order[i] = i
+	}
+	return reorderModel{title: title, items: items, order: order}
+}
+
+func (m reorderModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m reorderModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch key.String() {
+	case "ctrl+c", "q":
+		return m, tea.Quit
+	case "esc":
+		if !m.grabbed {
+			return m, tea.Quit
+		}
+		m.grabbed = false
+	case "enter", "ctrl+s":
+		m.saved = true
+		return m, tea.Quit
+	case " ":
+		m.grabbed = !m.grabbed
+	case "up", "k":
+		m.step(-1, m.grabbed)
+	case "down", "j":
+		m.step(1, m.grabbed)
+	case "shift+up", "K":
+		m.step(-1, true)
+	case "shift+down", "J":
+		m.step(1, true)
+	case "home", "g":
+		for m.cursor > 0 {
+			m.step(-1, m.grabbed)
+		}
+	case "end", "G":
+		for m.cursor < len(m.order)-1 {
+			m.step(1, m.grabbed)
+		}
+	}
+	return m, nil
+}
+
+// step moves the cursor by delta, carrying the item under it along when drag
+// is set
+func (m *reorderModel) step(delta int, drag bool) {
+	next := m.cursor + delta
+	if next < 0 || next >= len(m.order) {
+		return
+	}
+	if drag {
+		m.order[m.cursor], m.order[next] = m.order[next], m.order[m.cursor]
+	}
+	m.cursor = next
+}
+
+// changed reports whether the items are in a new order
+func (m reorderModel) changed() bool {
+	for i, j := range m.order {
+		if i != j {
+			return true
+		}
+	}
+	return false
+}
+
+func (m reorderModel) View() string {
+	var b strings.Builder
+	b.WriteString(reorderTitleStyle.Render(m.title))
+	b.WriteString("\n")
+	for i, j := range m.order {
+		line := fmt.Sprintf("%2d. %s", i+1, m.items[j])
+		switch {
+		case i == m.cursor && m.grabbed:
+			line = reorderGrabbedStyle.Render("≡ " + line)
+		case i == m.cursor:
+			line = reorderCursorStyle.Render("> " + line)
+		default:
+			line = "  " + line
+		}
+		b.WriteString(line + "\n")
+	}
+	help := "↑/↓ select • space grab/drop • shift+↑/↓ move • enter save • q quit"
+	if m.grabbed {
+		help = "↑/↓ move • space drop • enter save • esc drop"
+	}
+	b.WriteString(reorderHelpStyle.Render(help))
+	b.WriteString("\n")
+	return b.String()
+}
+
+// runReorder lets the user put items in a new order. It returns the original
+// position of each item in the new order, and false when nothing is to be
+// saved.
+func runReorder(title string, items []string) ([]int, bool, error) {
+	final, err := tea.NewProgram(newReorderModel(title, items)).Run()
+	if err != nil {
+		return nil, false, err
+	}
+	m := final.(reorderModel)
+	return m.order, m.saved && m.changed(), nil
+}
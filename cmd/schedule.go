@@ -0,0 +1,142 @@
+/*
+Copyright © 2024 Spencer Lyon spencerlyon2@gmail.com
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/charmbracelet/huh"
+	"github.com/sglyon/jupyteach/internal/model"
+	"github.com/spf13/cobra"
+)
+
+var (
+	scheduleDays   string
+	scheduleTime   string
+	scheduleTZ     string
+	scheduleSkip   []string
+	scheduleYes    bool
+	scheduleDryRun bool
+)
+
+// confirm asks the user a yes/no question
+func confirm(title string) (bool, error) {
+	var ok bool
+	err := huh.NewConfirm().Title(title).Value(&ok).Run()
+	return ok, err
+}
+
+// scheduleCmd represents the schedule command
+var scheduleCmd = &cobra.Command{
+	Use:   "schedule",
+	Short: "Set when each lecture becomes available from a meeting pattern",
+	Long: `Set the available_at time of every lecture from the days and time the class
+	meets.
+
+	Lectures are assigned, in the order of _course.yml, to the meetings
+	between the course start_date and end_date. Dates listed in --skip files
+	(holidays, breaks) are left out. A skip file is a yaml list whose entries
+	are either a date or a mapping with a name and a date or a from/to range:
+
+	- 2024-09-02
+	- name: Thanksgiving
+	  from: 2024-11-27
+	  to: 2024-11-29
+
+	The new schedule is shown, and _course.yml is only updated after you
+	confirm it (or with --yes).`,
+	Example: `  jupyteach schedule --days Tue,Thu --time 09:00 --tz America/New_York --skip holidays.yml`,
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		path, err := cmd.Flags().GetString("path")
+		if err != nil {
+			logger.Fatalf("Must provide a path")
+		}
+
+		days, err := model.ParseWeekdays(scheduleDays)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		hour, minute, err := model.ParseClock(scheduleTime)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		course, err := model.ParseCourseYaml(path)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		loc, err := course.Location()
+		if err != nil {
+			logger.Fatal(err)
+		}
+		if scheduleTZ != "" {
+			if loc, err = time.LoadLocation(scheduleTZ); err != nil {
+				logger.Fatalf("Unknown time zone %s: %s", scheduleTZ, err)
+			}
+		}
+		pattern := model.MeetingPattern{Days: days, Hour: hour, Minute: minute, Location: loc}
+		for _, file := range scheduleSkip {
+			skip, err := model.ReadSkipDates(file)
+			if err != nil {
+				logger.Fatal(err)
+			}
+			pattern.Skip = append(pattern.Skip, skip...)
+		}
+
+		if len(course.Lectures) == 0 {
+			logger.Fatal("The course has no lectures to schedule")
+		}
+		times, err := course.Schedule(pattern)
+		if err != nil {
+			logger.Fatal(err)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "LECTURE\tTITLE\tCURRENT\tNEW")
+		for i, cl := range course.Lectures {
+			current := cl.AvailableAt
+			if current == "" {
+				current = "-"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", cl.Directory, lectureTitle(path, cl.Directory), current, times[i].Format(displayTimeLayout))
+		}
+		w.Flush()
+
+		if scheduleDryRun {
+			return
+		}
+		if !scheduleYes {
+			ok, err := confirm("Write this schedule to _course.yml?")
+			if err != nil {
+				logger.Fatal(err)
+			}
+			if !ok {
+				return
+			}
+		}
+
+		for i := range course.Lectures {
+			course.Lectures[i].AvailableAt = times[i].Format(time.RFC3339)
+		}
+		if err := course.WriteYaml(path); err != nil {
+			logger.Fatal(err)
+		}
+		logger.Info("Updated the schedule in _course.yml", "lectures", len(course.Lectures))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(scheduleCmd)
+
+	scheduleCmd.Flags().StringVar(&scheduleDays, "days", "", "days the class meets, e.g. Tue,Thu")
+	scheduleCmd.Flags().StringVar(&scheduleTime, "time", "09:00", "time of day lectures become available (HH:MM)")
+	scheduleCmd.Flags().StringVar(&scheduleTZ, "tz", "", "IANA time zone of --time, e.g. America/New_York (default cli_timezone in _course.yml, then local time)")
+	scheduleCmd.Flags().StringSliceVar(&scheduleSkip, "skip", nil, "yaml file listing dates without lectures (repeatable)")
+	scheduleCmd.Flags().BoolVarP(&scheduleYes, "yes", "y", false, "write the schedule without asking")
+	scheduleCmd.Flags().BoolVar(&scheduleDryRun, "dry-run", false, "only show the schedule")
+	scheduleCmd.MarkFlagRequired("days")
+}
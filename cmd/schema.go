@@ -0,0 +1,82 @@
+/*
+Copyright © 2024 Spencer Lyon spencerlyon2@gmail.com
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/sglyon/jupyteach/internal/model"
+	"github.com/spf13/cobra"
+)
+
+var schemaOutDir string
+
+var schemas = map[string]func() *model.JSONSchema{
+	"course":  model.CourseSchema,
+	"lecture": model.LectureSchema,
+}
+
+func marshalSchema(s *model.JSONSchema) ([]byte, error) {
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+// schemaCmd represents the schema command
+var schemaCmd = &cobra.Command{
+	Use:   "schema {course|lecture}",
+	Short: "Print JSON Schemas for _course.yml and _lecture.yml",
+	Long: `Print the JSON Schema describing _course.yml or _lecture.yml.
+
+	With --out both schemas are written to course.schema.json and
+	lecture.schema.json in the given directory. Point yaml-language-server at
+	them by adding a comment like this to the top of the yaml file:
+
+	# yaml-language-server: $schema=.jupyteach/course.schema.json`,
+	Args:      cobra.MaximumNArgs(1),
+	ValidArgs: []string{"course", "lecture"},
+	Run: func(cmd *cobra.Command, args []string) {
+		if schemaOutDir != "" {
+			if err := os.MkdirAll(schemaOutDir, 0o755); err != nil {
+				logger.Fatal(err)
+			}
+			for name, build := range schemas {
+				b, err := marshalSchema(build())
+				if err != nil {
+					logger.Fatal(err)
+				}
+				out := filepath.Join(schemaOutDir, name+".schema.json")
+				if err := os.WriteFile(out, b, 0o644); err != nil {
+					logger.Fatal(err)
+				}
+				logger.Info("Wrote schema", "file", out)
+			}
+			return
+		}
+
+		if len(args) == 0 {
+			logger.Fatal("Must provide either `course` or `lecture`, or a directory with --out")
+		}
+		build, ok := schemas[args[0]]
+		if !ok {
+			logger.Fatalf("Unknown schema %s. Must be `course` or `lecture`", args[0])
+		}
+		b, err := marshalSchema(build())
+		if err != nil {
+			logger.Fatal(err)
+		}
+		fmt.Print(string(b))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+
+	schemaCmd.Flags().StringVarP(&schemaOutDir, "out", "o", "", "write both schemas to this directory")
+}
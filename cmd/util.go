@@ -248,6 +248,14 @@ func FilterChanged(changed map[string]string, files []model.SpecForZip) map[stri
 	for _, file := range files {
 		if _, ok := changed[file.Path]; ok {
 			filtered[file.Path] = changed[file.Path]
+			continue
+		}
+		// generated files change whenever one of their sources does
+		for _, dep := range file.DependsOn {
+			if _, ok := changed[dep]; ok {
+				filtered[file.Path] = "M"
+				break
+			}
 		}
 	}
 	return filtered
@@ -303,7 +311,7 @@ func commitAllAndUpdateServer(path, courseSlug, msg string) (committed, postedZi
 // zipDirectory zips the given directory and all its subdirectories, returning the zip contents as a byte slice.
 func createRepoZip(directory string) ([]byte, error) {
 
-	files, errList := getFilesToZipForRepoPush(path)
+	files, errList := getFilesToZipForRepoPush(directory)
 	if errList != nil {
 		return nil, errList
 	}
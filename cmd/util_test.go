@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"testing"
+
+	"github.com/sglyon/jupyteach/internal/model"
 )
 
 func TestSlugify(t *testing.T) {
@@ -18,7 +20,7 @@ func TestSlugify(t *testing.T) {
 	}
 
 	for _, test := range tests {
-		result := slugify(test.input, "-")
+		result := model.Slugify(test.input, "-")
 		if result != test.expected {
 			t.Errorf("slugify(%q) = %q, expected %q", test.input, result, test.expected)
 		}
@@ -39,9 +41,61 @@ func TestSlugifySep(t *testing.T) {
 	}
 
 	for _, test := range tests {
-		result := slugify(test.input, "_")
+		result := model.Slugify(test.input, "_")
+		if result != test.expected {
+			t.Errorf("slugify(%q) = %q, expected %q", test.input, result, test.expected)
+		}
+	}
+}
+
+func TestSlugifyUnicode(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"Don't Panic!", "dont-panic"},
+		{"Input/Output & Files?", "input-output-files"},
+		{"Café Crème: Économie", "cafe-creme-economie"},
+		{"Straße über Ærø", "strasse-uber-aero"},
+		{"🎉 Party   time 🎉", "party-time"},
+		{"Part 1 -- Intro...", "part-1-intro"},
+		{"“Quoted” title", "quoted-title"},
+		{"2024-04-18 review", "2024-04-18-review"},
+	}
+
+	for _, test := range tests {
+		result := model.Slugify(test.input, "-")
 		if result != test.expected {
 			t.Errorf("slugify(%q) = %q, expected %q", test.input, result, test.expected)
 		}
 	}
 }
+
+func TestSlugRules(t *testing.T) {
+	rules := model.SlugRules{
+		Separator:    "_",
+		MaxLength:    20,
+		Replacements: map[string]string{"&": "and", "C++": "cpp"},
+	}
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"Pointers & C++", "pointers_and_cpp"},
+		{"A very long lecture title about things", "a_very_long_lecture"},
+		{"Supercalifragilisticexpialidocious", "supercalifragilistic"},
+	}
+	for _, test := range tests {
+		if result := rules.Slugify(test.input); result != test.expected {
+			t.Errorf("Slugify(%q) = %q, expected %q", test.input, result, test.expected)
+		}
+	}
+
+	unicode := model.SlugRules{KeepUnicode: true}
+	if result := unicode.Slugify("Ελληνικά: Μάθημα 1"); result != "ελληνικά-μάθημα-1" {
+		t.Errorf("Slugify kept %q", result)
+	}
+	if result := model.Slugify("日本語", "-"); result != "" {
+		t.Errorf("expected no ASCII slug, got %q", result)
+	}
+}
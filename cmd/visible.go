@@ -0,0 +1,128 @@
+/*
+Copyright © 2024 Spencer Lyon spencerlyon2@gmail.com
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sglyon/jupyteach/internal/model"
+	"github.com/spf13/cobra"
+)
+
+var visibleAsOf string
+
+// displayTimeLayout is how times are shown to instructors
+const displayTimeLayout = "Mon 2006-01-02 15:04 MST"
+
+// parseAsOf parses the --as-of flag of a command, defaulting to now. Local
+// times are in the course timezone and expressions like start+2w are allowed.
+func parseAsOf(path, s string) (time.Time, error) {
+	if s == "" || s == "now" {
+		return time.Now(), nil
+	}
+	course, err := model.ParseCourseYaml(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	times, err := course.TimeResolver(time.Now())
+	if err != nil {
+		return time.Time{}, err
+	}
+	return times.Parse(s)
+}
+
+// lectureTitle returns the title of the lecture in dir, or dir itself if the
+// lecture can't be read
+func lectureTitle(path, dir string) string {
+	lecture, err := model.ParseLectureYaml(filepath.Join(path, dir, "_lecture.yml"))
+	if err != nil || lecture.Title == "" {
+		return dir
+	}
+	return lecture.Title
+}
+
+// visibleCmd represents the visible command
+var visibleCmd = &cobra.Command{
+	Use:   "visible",
+	Short: "Show which lectures students can see at a given time",
+	Long: `Show which lectures students can see at a given time.
+
+	Lectures become visible at their available_at time in _course.yml, or are
+	always visible when it is not set. Pass --as-of to look at another moment
+	than now, for example --as-of "2024-09-02 08:00" to see what students will
+	find on the first Monday morning of term, or --as-of start+2w. Times are
+	shown in the cli_timezone of _course.yml.
+
+	A timeline of the releases between the course start_date and end_date
+	follows, marking those still to come.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		path, err := cmd.Flags().GetString("path")
+		if err != nil {
+			logger.Fatalf("Must provide a path")
+		}
+		asOf, err := parseAsOf(path, visibleAsOf)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		course, err := model.ParseCourseYaml(path)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		times, err := course.TimeResolver(time.Now())
+		if err != nil {
+			logger.Fatal(err)
+		}
+
+		fmt.Printf("Lectures visible at %s:\n\n", asOf.In(times.Location).Format(displayTimeLayout))
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		for _, cl := range course.Lectures {
+			visible, err := times.VisibleAt(cl, asOf)
+			if err != nil {
+				logger.Fatal(err)
+			}
+			mark, when := "✓", "always"
+			if !visible {
+				mark = "✗"
+			}
+			if at, ok, _ := times.AvailableTime(cl); ok {
+				when = at.In(times.Location).Format(displayTimeLayout)
+			}
+			fmt.Fprintf(w, "  %s\t%s\t%s\t%s\n", mark, cl.Directory, lectureTitle(path, cl.Directory), when)
+		}
+		w.Flush()
+
+		releases, err := course.Timeline()
+		if err != nil {
+			logger.Fatal(err)
+		}
+		if len(releases) == 0 {
+			return
+		}
+		fmt.Print("\nTimeline")
+		if course.StartDate != "" || course.EndDate != "" {
+			fmt.Printf(" (%s to %s)", course.StartDate, course.EndDate)
+		}
+		fmt.Print(":\n\n")
+		w = tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		for _, r := range releases {
+			status := "released"
+			if r.At.After(asOf) {
+				status = "upcoming"
+			}
+			fmt.Fprintf(w, "  %s\t%s\t%s\t%s\n", r.At.In(times.Location).Format(displayTimeLayout), r.Lecture.Directory, lectureTitle(path, r.Lecture.Directory), status)
+		}
+		w.Flush()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(visibleCmd)
+
+	visibleCmd.Flags().StringVar(&visibleAsOf, "as-of", "now", "time to check visibility at, e.g. \"2024-09-02 08:00\" (course timezone), start+2w or RFC3339")
+}
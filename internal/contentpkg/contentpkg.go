@@ -0,0 +1,58 @@
+// Package contentpkg writes the zip packages shared by the IMS formats (QTI,
+// Common Cartridge and SCORM): an imsmanifest.xml next to the files it lists
+package contentpkg
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+)
+
+// File is a single file of a package
+type File struct {
+	Href    string
+	Content []byte
+}
+
+// MarshalXML encodes v as an indented xml document, starting with the xml
+// header
+func MarshalXML(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+// WriteZip writes manifest as imsmanifest.xml followed by files to a zip file.
+// Only the first of several files with the same href is written, as a zip
+// can't hold two entries of the same name.
+func WriteZip(w io.Writer, manifest interface{}, files ...File) error {
+	content, err := MarshalXML(manifest)
+	if err != nil {
+		return err
+	}
+
+	zw := zip.NewWriter(w)
+	files = append([]File{{Href: "imsmanifest.xml", Content: content}}, files...)
+	written := make(map[string]bool, len(files))
+	for _, f := range files {
+		if written[f.Href] {
+			continue
+		}
+		written[f.Href] = true
+		fw, err := zw.Create(f.Href)
+		if err != nil {
+			return err
+		}
+		if _, err := fw.Write(f.Content); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
@@ -0,0 +1,46 @@
+package contentpkg
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+)
+
+type manifest struct {
+	XMLName xml.Name `xml:"manifest"`
+	ID      string   `xml:"identifier,attr"`
+}
+
+func TestWriteZip(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteZip(&buf, manifest{ID: "demo"}, File{Href: "a/page.html", Content: []byte("<p>hi</p>")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"imsmanifest.xml": xml.Header + "<manifest identifier=\"demo\"></manifest>\n",
+		"a/page.html":     "<p>hi</p>",
+	}
+	if len(r.File) != len(want) || r.File[0].Name != "imsmanifest.xml" {
+		t.Fatalf("zip holds %d files starting with %s, want the manifest first", len(r.File), r.File[0].Name)
+	}
+	for _, f := range r.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, _ := io.ReadAll(rc)
+		rc.Close()
+		if strings.TrimSpace(string(got)) != strings.TrimSpace(want[f.Name]) {
+			t.Errorf("%s = %q, want %q", f.Name, got, want[f.Name])
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 
@@ -236,3 +237,58 @@ func CommitAll(path, message string) (bool, error) {
 
 	return committed, err
 }
+
+// isTracked reports whether git tracks any file at or under name in the
+// repository at path
+func isTracked(path, name string) bool {
+	var tracked string
+	err := WithDirectory(path, func() error {
+		var errOut error
+		tracked, errOut = lib.Raw("ls-files", func(g *types.Cmd) {
+			g.AddOptions("--")
+			g.AddOptions(name)
+		})
+		return errOut
+	})
+	return err == nil && strings.TrimSpace(tracked) != ""
+}
+
+// Move renames src to dst, both relative to the repository at path, with
+// `git mv` so the history follows the files. A directory git doesn't track
+// yet is renamed as is.
+func Move(path, src, dst string) error {
+	if !isTracked(path, src) {
+		return os.Rename(filepath.Join(path, src), filepath.Join(path, dst))
+	}
+
+	return WithDirectory(path, func() error {
+		s, errOut := lib.Raw("mv", func(g *types.Cmd) {
+			g.AddOptions(src)
+			g.AddOptions(dst)
+		})
+		if errOut != nil {
+			log.Error(s)
+		}
+		return errOut
+	})
+}
+
+// Remove deletes the file name, relative to the repository at path, with
+// `git rm`. A file git doesn't track is simply deleted.
+func Remove(path, name string) error {
+	if !isTracked(path, name) {
+		return os.Remove(filepath.Join(path, name))
+	}
+
+	return WithDirectory(path, func() error {
+		s, errOut := lib.Raw("rm", func(g *types.Cmd) {
+			g.AddOptions("--quiet")
+			g.AddOptions("--")
+			g.AddOptions(name)
+		})
+		if errOut != nil {
+			log.Error(s)
+		}
+		return errOut
+	})
+}
@@ -0,0 +1,188 @@
+// Package ical writes the release dates and due dates of a course as an
+// iCalendar (RFC 5545) file that calendar apps can import or subscribe to
+package ical
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sglyon/jupyteach/internal/model"
+)
+
+const (
+	prodID      = "-//jupyteach//jupyteach//EN"
+	utcLayout   = "20060102T150405Z"
+	maxLineSize = 75
+)
+
+// Event is a single moment in the course calendar
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Start       time.Time
+}
+
+// Calendar holds the events of a course, in course order
+type Calendar struct {
+	Name   string
+	Events []Event
+}
+
+// Build returns a calendar with an event for the release of every lecture
+// with an available_at and for the cli_due_at of every quiz and upload
+// block. The lectures are read from the course in dirname.
+func Build(dirname string, course *model.CourseYaml) (*Calendar, error) {
+	cal := &Calendar{Name: course.Name}
+	if cal.Name == "" {
+		cal.Name = course.Slug
+	}
+	times, err := course.TimeResolver(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	for _, cl := range course.Lectures {
+		lecture, err := model.ParseLectureYaml(filepath.Join(dirname, cl.Directory, "_lecture.yml"))
+		if err != nil {
+			return nil, err
+		}
+		title := lecture.Title
+		if title == "" {
+			title = cl.Directory
+		}
+
+		at, ok, err := times.AvailableTime(cl)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			cal.Events = append(cal.Events, Event{
+				UID:         uid(course.Slug, cl.Directory, "release"),
+				Summary:     title + " released",
+				Description: lecture.Description,
+				Start:       at,
+			})
+		}
+
+		keys := map[string]int{}
+		for i, cb := range lecture.ContentBlocks {
+			if cb.DueAt == "" || (cb.Type != "quiz" && cb.NUploads == 0) {
+				continue
+			}
+			due, _, err := times.DueTime(cb)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", cl.Directory, err)
+			}
+			name := cb.Title
+			if name == "" {
+				name = fmt.Sprintf("%s %d", cb.Type, i+1)
+			}
+			cal.Events = append(cal.Events, Event{
+				UID:         uid(course.Slug, cl.Directory, blockKey(cb, i, keys)+"-due"),
+				Summary:     fmt.Sprintf("%s due (%s)", name, title),
+				Description: cb.Description,
+				Start:       due,
+			})
+		}
+	}
+	return cal, nil
+}
+
+// uid returns a stable identifier so calendar apps update events in place
+// when the file is imported again
+func uid(slug, dir, what string) string {
+	return fmt.Sprintf("%s-%s-%s@jupyteach", slug, filepath.ToSlash(dir), what)
+}
+
+// blockKey names a content block in the UIDs of its events so they survive
+// moving the block within the lecture: by the id the server gave it, else by
+// its file or title, and by its position only when it has none of these.
+// keys counts the names already used in the lecture, blocks sharing a name
+// get a number after it.
+func blockKey(cb model.ContentBlockYaml, i int, keys map[string]int) string {
+	var key string
+	switch {
+	case cb.ContentBlockID != 0:
+		key = fmt.Sprintf("block-%d", cb.ContentBlockID)
+	case cb.Filename != "":
+		key = "file-" + filepath.ToSlash(cb.Filename)
+	case cb.Title != "":
+		key = "title-" + model.Slugify(cb.Title, "-")
+	default:
+		key = fmt.Sprintf("position-%d", i+1)
+	}
+	keys[key]++
+	if n := keys[key]; n > 1 {
+		key = fmt.Sprintf("%s-%d", key, n)
+	}
+	return key
+}
+
+// Write writes the calendar in iCalendar format. Times are written in UTC.
+func (c *Calendar) Write(w io.Writer) error {
+	stamp := time.Now().UTC().Format(utcLayout)
+
+	var b strings.Builder
+	line := func(name, value string) {
+		b.WriteString(fold(name + ":" + value))
+	}
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", prodID)
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	if c.Name != "" {
+		line("X-WR-CALNAME", escape(c.Name))
+	}
+	for _, e := range c.Events {
+		line("BEGIN", "VEVENT")
+		line("UID", escape(e.UID))
+		line("DTSTAMP", stamp)
+		line("DTSTART", e.Start.UTC().Format(utcLayout))
+		line("SUMMARY", escape(e.Summary))
+		if e.Description != "" {
+			line("DESCRIPTION", escape(e.Description))
+		}
+		line("END", "VEVENT")
+	}
+	line("END", "VCALENDAR")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// escape escapes a TEXT value
+func escape(s string) string {
+	return escaper.Replace(strings.TrimSpace(s))
+}
+
+// fold ends a content line with CRLF, splitting it into lines of at most 75
+// octets without breaking a UTF-8 character. Continuation lines start with a
+// space.
+func fold(line string) string {
+	var b strings.Builder
+	limit := maxLineSize
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		limit = maxLineSize - 1
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+	return b.String()
+}
+
+func isRuneStart(c byte) bool {
+	return c&0xC0 != 0x80
+}
@@ -0,0 +1,91 @@
+package ical
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sglyon/jupyteach/internal/model"
+)
+
+func TestBuildAndWrite(t *testing.T) {
+	dir := t.TempDir()
+	lecture := `title: Intro, part 1
+description: The basics
+content_blocks:
+  - type: quiz
+    title: Warmup
+    content_block_id: 42
+    cli_due_at: 2024-09-05T23:59:00-04:00
+    quiz:
+      questions: []
+  - type: notebook
+    filename: hw.ipynb
+    n_uploads: 1
+    cli_due_at: start+4d 23:59
+  - type: markdown
+    filename: notes.md
+    cli_due_at: 2024-09-06T23:59:00Z
+`
+	if err := os.MkdirAll(filepath.Join(dir, "01-intro"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "01-intro", "_lecture.yml"), []byte(lecture), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	course := &model.CourseYaml{
+		Slug:      "demo",
+		Timezone:  "America/New_York",
+		StartDate: "2024-09-02",
+		Lectures: []model.CourseLectureYaml{
+			{Directory: "01-intro", AvailableAt: "2024-09-03T09:00:00-04:00"},
+		},
+	}
+
+	cal, err := Build(dir, course)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cal.Events) != 3 {
+		t.Fatalf("got %d events, want 3: %+v", len(cal.Events), cal.Events)
+	}
+
+	var buf bytes.Buffer
+	if err := cal.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"X-WR-CALNAME:demo\r\n",
+		"UID:demo-01-intro-release@jupyteach\r\n",
+		"UID:demo-01-intro-block-42-due@jupyteach\r\n",
+		"UID:demo-01-intro-file-hw.ipynb-due@jupyteach\r\n",
+		"DTSTART:20240903T130000Z\r\n",
+		"SUMMARY:Intro\\, part 1 released\r\n",
+		"SUMMARY:Warmup due (Intro\\, part 1)\r\n",
+		"DTSTART:20240906T035900Z\r\n",
+		"SUMMARY:notebook 2 due (Intro\\, part 1)\r\n",
+		"DTSTART:20240907T035900Z\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in\n%s", want, out)
+		}
+	}
+}
+
+func TestFold(t *testing.T) {
+	line := "SUMMARY:" + strings.Repeat("é", 50)
+	folded := fold(line)
+	for _, l := range strings.Split(strings.TrimSuffix(folded, "\r\n"), "\r\n") {
+		if len(l) > maxLineSize {
+			t.Errorf("line of %d octets: %q", len(l), l)
+		}
+	}
+	if got := strings.ReplaceAll(strings.TrimSuffix(folded, "\r\n"), "\r\n ", ""); got != line {
+		t.Errorf("unfolded to %q, want %q", got, line)
+	}
+}
@@ -0,0 +1,250 @@
+package imscc
+
+import (
+	"encoding/xml"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+
+	"github.com/sglyon/jupyteach/internal/model"
+	"github.com/sglyon/jupyteach/internal/render"
+)
+
+// Common Cartridge quizzes use the QTI 1.2 profile described in the
+// Common Cartridge specification, not QTI 2.1
+
+const (
+	qtiNamespace      = "http://www.imsglobal.org/xsd/ims_qtiasiv1p2"
+	qtiSchemaLocation = "http://www.imsglobal.org/xsd/ims_qtiasiv1p2 http://www.imsglobal.org/profile/cc/ccv1p3/ccv1p3_qtiasiv1p2p1_v1p0.xsd"
+	responseIdent     = "response1"
+)
+
+type metadataField struct {
+	Label string `xml:"fieldlabel"`
+	Entry string `xml:"fieldentry"`
+}
+
+type qtiMetadata struct {
+	Fields []metadataField `xml:"qtimetadatafield"`
+}
+
+type mattext struct {
+	TextType string `xml:"texttype,attr"`
+	Text     string `xml:",chardata"`
+}
+
+type material struct {
+	Text mattext `xml:"mattext"`
+}
+
+type responseLabel struct {
+	Ident    string    `xml:"ident,attr"`
+	RShuffle string    `xml:"rshuffle,attr,omitempty"`
+	Material *material `xml:"material,omitempty"`
+}
+
+type renderChoice struct {
+	Labels []responseLabel `xml:"response_label"`
+}
+
+type responseLid struct {
+	Ident        string       `xml:"ident,attr"`
+	RCardinality string       `xml:"rcardinality,attr"`
+	Render       renderChoice `xml:"render_choice"`
+}
+
+type renderFib struct {
+	Label responseLabel `xml:"response_label"`
+}
+
+type responseStr struct {
+	Ident        string    `xml:"ident,attr"`
+	RCardinality string    `xml:"rcardinality,attr"`
+	Render       renderFib `xml:"render_fib"`
+}
+
+type presentation struct {
+	Material    material     `xml:"material"`
+	ResponseLid *responseLid `xml:"response_lid,omitempty"`
+	ResponseStr *responseStr `xml:"response_str,omitempty"`
+}
+
+type decvar struct {
+	VarName  string `xml:"varname,attr"`
+	VarType  string `xml:"vartype,attr"`
+	MinValue string `xml:"minvalue,attr"`
+	MaxValue string `xml:"maxvalue,attr"`
+}
+
+// conditionVar is written as text since it nests and/not/varequal freely
+type conditionVar struct {
+	Inner string `xml:",innerxml"`
+}
+
+type setVar struct {
+	VarName string `xml:"varname,attr"`
+	Action  string `xml:"action,attr"`
+	Value   string `xml:",chardata"`
+}
+
+type respCondition struct {
+	Continue  string       `xml:"continue,attr"`
+	Condition conditionVar `xml:"conditionvar"`
+	SetVar    *setVar      `xml:"setvar,omitempty"`
+}
+
+type resprocessing struct {
+	Outcomes   []decvar        `xml:"outcomes>decvar"`
+	Conditions []respCondition `xml:"respcondition"`
+}
+
+type assessmentItem struct {
+	Ident        string        `xml:"ident,attr"`
+	Title        string        `xml:"title,attr"`
+	Metadata     qtiMetadata   `xml:"itemmetadata>qtimetadata"`
+	Presentation presentation  `xml:"presentation"`
+	Processing   resprocessing `xml:"resprocessing"`
+}
+
+type section struct {
+	Ident string           `xml:"ident,attr"`
+	Items []assessmentItem `xml:"item"`
+}
+
+type assessment struct {
+	Ident    string      `xml:"ident,attr"`
+	Title    string      `xml:"title,attr"`
+	Metadata qtiMetadata `xml:"qtimetadata"`
+	Section  section     `xml:"section"`
+}
+
+type questestinterop struct {
+	XMLName        xml.Name   `xml:"questestinterop"`
+	Xmlns          string     `xml:"xmlns,attr"`
+	Xsi            string     `xml:"xmlns:xsi,attr"`
+	SchemaLocation string     `xml:"xsi:schemaLocation,attr"`
+	Assessment     assessment `xml:"assessment"`
+}
+
+// maxAttempts converts Quiz.MaxAttempts to the values allowed by
+// cc_maxattempts
+func maxAttempts(n int) string {
+	if n < 1 || n > 5 {
+		return "unlimited"
+	}
+	return strconv.Itoa(n)
+}
+
+func newAssessment(ident, title string, quiz model.Quiz) (questestinterop, error) {
+	a := assessment{
+		Ident: ident,
+		Title: title,
+		Metadata: qtiMetadata{Fields: []metadataField{
+			{"cc_profile", "cc.exam.v0p1"},
+			{"qmd_assessmenttype", "Examination"},
+			{"cc_maxattempts", maxAttempts(quiz.MaxAttempts)},
+		}},
+		Section: section{Ident: "root_section"},
+	}
+	for i, q := range quiz.Questions {
+		item, err := newAssessmentItem(fmt.Sprintf("%s-q%d", ident, i+1), fmt.Sprintf("Question %d", i+1), q)
+		if err != nil {
+			return questestinterop{}, fmt.Errorf("question %d: %w", i+1, err)
+		}
+		a.Section.Items = append(a.Section.Items, item)
+	}
+	return questestinterop{
+		Xmlns:          qtiNamespace,
+		Xsi:            xsiNamespace,
+		SchemaLocation: qtiSchemaLocation,
+		Assessment:     a,
+	}, nil
+}
+
+func varequal(ident string) string {
+	return fmt.Sprintf(`<varequal respident=%q>%s</varequal>`, responseIdent, ident)
+}
+
+// newAssessmentItem converts a question. Selection questions become multiple
+// choice or multiple response items, all others essays graded by hand.
+func newAssessmentItem(ident, title string, q model.Question) (assessmentItem, error) {
+	text, err := render.Markdown([]byte(q.QuestionText))
+	if err != nil {
+		return assessmentItem{}, err
+	}
+	if q.StartingCode != "" {
+		text = append(text, "<pre><code>"+html.EscapeString(q.StartingCode)+"</code></pre>\n"...)
+	}
+
+	item := assessmentItem{
+		Ident:        ident,
+		Title:        title,
+		Presentation: presentation{Material: material{Text: mattext{TextType: "text/html", Text: string(text)}}},
+		Processing: resprocessing{Outcomes: []decvar{
+			{VarName: "SCORE", VarType: "Decimal", MinValue: "0", MaxValue: "100"},
+		}},
+	}
+	weighting := metadataField{"cc_weighting", strconv.Itoa(q.Points)}
+
+	switch q.QuestionType {
+	case "single_selection", "multiple_selection":
+		correct := []string{q.Solution}
+		profile, cardinality := "cc.multiple_choice.v0p1", "Single"
+		if q.QuestionType == "multiple_selection" {
+			if correct, err = model.ParseMultipleSelectionSolution(q.Solution); err != nil {
+				return item, err
+			}
+			profile, cardinality = "cc.multiple_response.v0p1", "Multiple"
+		}
+
+		lid := &responseLid{Ident: responseIdent, RCardinality: cardinality}
+		var condition strings.Builder
+		nCorrect := 0
+		for i, option := range q.Options {
+			id := fmt.Sprintf("%s-c%d", ident, i+1)
+			lid.Render.Labels = append(lid.Render.Labels, responseLabel{
+				Ident:    id,
+				Material: &material{Text: mattext{TextType: "text/plain", Text: option}},
+			})
+			isCorrect := false
+			for _, c := range correct {
+				isCorrect = isCorrect || c == option
+			}
+			switch {
+			case isCorrect:
+				nCorrect++
+				condition.WriteString(varequal(id))
+			case cardinality == "Multiple":
+				condition.WriteString("<not>" + varequal(id) + "</not>")
+			}
+		}
+		if nCorrect == 0 {
+			return item, fmt.Errorf("solution does not match any of the options")
+		}
+		inner := condition.String()
+		if cardinality == "Multiple" {
+			inner = "<and>" + inner + "</and>"
+		}
+
+		item.Metadata.Fields = []metadataField{{"cc_profile", profile}, weighting}
+		item.Presentation.ResponseLid = lid
+		item.Processing.Conditions = []respCondition{{
+			Continue:  "No",
+			Condition: conditionVar{Inner: inner},
+			SetVar:    &setVar{VarName: "SCORE", Action: "Set", Value: "100"},
+		}}
+	default:
+		item.Metadata.Fields = []metadataField{{"cc_profile", "cc.essay.v0p1"}, weighting}
+		item.Presentation.ResponseStr = &responseStr{
+			Ident:        responseIdent,
+			RCardinality: "Single",
+			Render:       renderFib{Label: responseLabel{Ident: "answer1", RShuffle: "No"}},
+		}
+		item.Processing.Conditions = []respCondition{{
+			Continue:  "No",
+			Condition: conditionVar{Inner: "<other/>"},
+		}}
+	}
+	return item, nil
+}
@@ -0,0 +1,266 @@
+// Package imscc writes a course as an IMS Common Cartridge 1.3 package that
+// can be imported into Canvas, Moodle, Blackboard and other learning
+// management systems
+package imscc
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/sglyon/jupyteach/internal/contentpkg"
+	"github.com/sglyon/jupyteach/internal/model"
+	"github.com/sglyon/jupyteach/internal/notebook"
+	"github.com/sglyon/jupyteach/internal/render"
+)
+
+const (
+	xsiNamespace     = "http://www.w3.org/2001/XMLSchema-instance"
+	webLinkNamespace = "http://www.imsglobal.org/xsd/imsccv1p3/imswl_v1p3"
+
+	webContentType = "webcontent"
+	webLinkType    = "imswl_xmlv1p3"
+	assessmentType = "imsqti_xmlv1p2/imscc_xmlv1p3/assessment"
+)
+
+type resource struct {
+	Identifier string
+	Type       string
+	Href       string
+	Files      []string
+}
+
+// item is an entry of a module, pointing at a resource
+type item struct {
+	Identifier string
+	Title      string
+	Resource   string
+}
+
+// module holds the content of a lecture
+type module struct {
+	Identifier string
+	Title      string
+	Items      []item
+}
+
+// Cartridge is a course converted to Common Cartridge resources. Lectures
+// become modules, in the order of CourseYaml.Lectures.
+type Cartridge struct {
+	Title     string
+	files     []contentpkg.File
+	hrefs     map[string]bool
+	resources []resource
+	modules   []module
+	n         int
+
+	// Skipped lists content blocks that could not be converted
+	Skipped []string
+}
+
+// nextID returns a new identifier for a resource or item
+func (c *Cartridge) nextID(prefix string) string {
+	c.n++
+	return fmt.Sprintf("%s-%d", prefix, c.n)
+}
+
+// addResource adds a resource made of files and returns its identifier. A
+// file already in the cartridge, such as a notebook two blocks use, is
+// written once and listed by every resource using it.
+func (c *Cartridge) addResource(typ, href string, files ...contentpkg.File) string {
+	id := c.nextID("resource")
+	r := resource{Identifier: id, Type: typ, Href: href}
+	for _, f := range files {
+		if !c.hrefs[f.Href] {
+			c.hrefs[f.Href] = true
+			c.files = append(c.files, f)
+		}
+		r.Files = append(r.Files, f.Href)
+	}
+	c.resources = append(c.resources, r)
+	return id
+}
+
+// Build converts the course in the directory dirname. The source files
+// (_course.yml, syllabus.md, each _lecture.yml and the content they point at)
+// are included so the cartridge can also serve as a copy of the course.
+func Build(dirname string, course *model.CourseYaml, opts model.ZipOptions) (*Cartridge, error) {
+	c := &Cartridge{Title: course.Name, hrefs: map[string]bool{}}
+	if c.Title == "" {
+		c.Title = course.Slug
+	}
+	stripOutputs := opts.StripOutputs || course.StripNotebookOutputs
+
+	source := []contentpkg.File{}
+	courseYaml, err := os.ReadFile(filepath.Join(dirname, "_course.yml"))
+	if err != nil {
+		return nil, err
+	}
+	source = append(source, contentpkg.File{Href: "_course.yml", Content: courseYaml})
+
+	if syllabus, err := os.ReadFile(filepath.Join(dirname, "syllabus.md")); err == nil {
+		source = append(source, contentpkg.File{Href: "syllabus.md", Content: syllabus})
+		body, err := render.Markdown(syllabus)
+		if err != nil {
+			return nil, err
+		}
+		assets, err := localFiles(dirname, "", "syllabus.md")
+		if err != nil {
+			return nil, err
+		}
+		page := contentpkg.File{Href: "syllabus.html", Content: render.Page("Syllabus", body)}
+		res := c.addResource(webContentType, "syllabus.html", append([]contentpkg.File{page}, assets...)...)
+		c.modules = append(c.modules, module{
+			Identifier: c.nextID("module"),
+			Title:      "Syllabus",
+			Items:      []item{{Identifier: c.nextID("item"), Title: "Syllabus", Resource: res}},
+		})
+	}
+
+	for _, cl := range course.Lectures {
+		lectureDir := filepath.Join(dirname, cl.Directory)
+		lectureYaml, err := os.ReadFile(filepath.Join(lectureDir, "_lecture.yml"))
+		if err != nil {
+			return nil, err
+		}
+		source = append(source, contentpkg.File{Href: path.Join(filepath.ToSlash(cl.Directory), "_lecture.yml"), Content: lectureYaml})
+
+		lecture, err := model.ParseLectureYaml(filepath.Join(lectureDir, "_lecture.yml"))
+		if err != nil {
+			return nil, err
+		}
+		m := module{Identifier: c.nextID("module"), Title: lecture.Title}
+		if m.Title == "" {
+			m.Title = cl.Directory
+		}
+
+		for i, cb := range lecture.ContentBlocks {
+			if cb.Type == "quiz" && model.IsQuizMarkdown(cb.Filename) {
+				content, err := os.ReadFile(filepath.Join(lectureDir, cb.Filename))
+				if err != nil {
+					return nil, err
+				}
+				source = append(source, contentpkg.File{Href: path.Join(filepath.ToSlash(cl.Directory), filepath.ToSlash(cb.Filename)), Content: content})
+			}
+
+			title := cb.Title
+			if title == "" {
+				title = fmt.Sprintf("%s %d", cb.Type, i+1)
+			}
+			res, err := c.addBlock(cl.Directory, lectureDir, title, cb, stripOutputs)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s: %w", cl.Directory, title, err)
+			}
+			if res == "" {
+				c.Skipped = append(c.Skipped, fmt.Sprintf("%s: %s (%s)", cl.Directory, title, cb.Type))
+				continue
+			}
+			m.Items = append(m.Items, item{Identifier: c.nextID("item"), Title: title, Resource: res})
+		}
+		c.modules = append(c.modules, m)
+	}
+
+	c.addResource(webContentType, "", source...)
+	return c, nil
+}
+
+// addBlock adds the resource for a content block and returns its
+// identifier, or "" if the block type is not supported
+func (c *Cartridge) addBlock(dir, lectureDir, title string, cb model.ContentBlockYaml, stripOutputs bool) (string, error) {
+	href := path.Join(filepath.ToSlash(dir), filepath.ToSlash(cb.Filename))
+
+	switch cb.Type {
+	case "notebook":
+		content, err := os.ReadFile(filepath.Join(lectureDir, cb.Filename))
+		if err != nil {
+			return "", err
+		}
+		if stripOutputs {
+			nb, err := notebook.Parse(content)
+			if err != nil {
+				return "", err
+			}
+			nb.StripOutputs()
+			if content, err = notebook.Marshal(nb); err != nil {
+				return "", err
+			}
+		}
+		// the notebook comes first so the copy without outputs is the one
+		// kept, LocalFiles lists it again
+		assets, err := localFiles(lectureDir, dir, cb.Filename)
+		if err != nil {
+			return "", err
+		}
+		return c.addResource(webContentType, href, append([]contentpkg.File{{Href: href, Content: content}}, assets...)...), nil
+	case "markdown":
+		content, err := os.ReadFile(filepath.Join(lectureDir, cb.Filename))
+		if err != nil {
+			return "", err
+		}
+		body, err := render.Markdown(content)
+		if err != nil {
+			return "", err
+		}
+		// the markdown file and the images and files it links to, so the
+		// links of the page work
+		assets, err := localFiles(lectureDir, dir, cb.Filename)
+		if err != nil {
+			return "", err
+		}
+		page := strings.TrimSuffix(href, path.Ext(href)) + ".html"
+		return c.addResource(
+			webContentType, page,
+			append([]contentpkg.File{{Href: page, Content: render.Page(title, body)}}, assets...)...,
+		), nil
+	case "link", "video":
+		url := cb.URL
+		if cb.Type == "video" {
+			url = cb.VideoURL()
+		}
+		link, err := contentpkg.MarshalXML(webLink{Xmlns: webLinkNamespace, Title: title, URL: webLinkURL{Href: url, Target: "_blank"}})
+		if err != nil {
+			return "", err
+		}
+		id := c.nextID("link")
+		return c.addResource(webLinkType, "", contentpkg.File{Href: path.Join("links", id+".xml"), Content: link}), nil
+	case "quiz":
+		quiz, err := cb.LoadQuiz(lectureDir)
+		if err != nil {
+			return "", err
+		}
+		id := c.nextID("quiz")
+		a, err := newAssessment(id, title, quiz)
+		if err != nil {
+			return "", err
+		}
+		content, err := contentpkg.MarshalXML(a)
+		if err != nil {
+			return "", err
+		}
+		return c.addResource(assessmentType, "", contentpkg.File{Href: path.Join(id, "assessment.xml"), Content: content}), nil
+	}
+	return "", nil
+}
+
+// localFiles reads name, a markdown file or notebook in dir, and the local
+// files it links to or embeds, see render.LocalFiles. They are placed under
+// prefix in the cartridge.
+func localFiles(dir, prefix, name string) ([]contentpkg.File, error) {
+	var files []contentpkg.File
+	for _, rel := range render.LocalFiles(dir, []string{name}, nil) {
+		content, err := os.ReadFile(filepath.Join(dir, rel))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, contentpkg.File{Href: path.Join(prefix, filepath.ToSlash(rel)), Content: content})
+	}
+	return files, nil
+}
+
+// WriteZip writes the cartridge, with its imsmanifest.xml, as a zip file
+func (c *Cartridge) WriteZip(w io.Writer) error {
+	return contentpkg.WriteZip(w, c.manifest(), c.files...)
+}
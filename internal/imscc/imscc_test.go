@@ -0,0 +1,166 @@
+package imscc
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sglyon/jupyteach/internal/model"
+)
+
+const lectureYaml = `title: Intro
+content_blocks:
+- type: notebook
+  title: Notebook
+  filename: intro.ipynb
+- type: markdown
+  title: Notes
+  filename: notes.md
+- type: notebook
+  title: Notebook again
+  filename: intro.ipynb
+- type: link
+  title: Docs
+  url: https://example.com/docs
+- type: video
+  title: Welcome
+  youtube_video_id: abc123
+- type: quiz
+  title: Check
+  quiz:
+    questions:
+    - question_type: multiple_selection
+      question_text: Pick the even numbers
+      points: 2
+      options: ["1", "2", "4"]
+      solution: '["2", "4"]'
+`
+
+func TestBuild(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"_course.yml":        "slug: demo\nname: Demo Course\nlectures:\n- directory: intro\n",
+		"syllabus.md":        "# Syllabus\n",
+		"intro/_lecture.yml": lectureYaml,
+		"intro/intro.ipynb":  `{"cells": [], "metadata": {}, "nbformat": 4, "nbformat_minor": 5}`,
+		"intro/notes.md":     "# Notes\n\nSome *text*.\n\n![plot](img/plot.png) and [data](data.csv)\n",
+		"intro/img/plot.png": "png",
+		"intro/data.csv":     "a,b\n",
+		"intro/answers.py":   "print(42)\n",
+	}
+	for name, content := range files {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	course, err := model.ParseCourseYaml(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := Build(dir, course, model.ZipOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Skipped) != 0 {
+		t.Errorf("unexpected skipped blocks %v", c.Skipped)
+	}
+
+	var buf bytes.Buffer
+	if err := c.WriteZip(&buf); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	contents := map[string]string{}
+	for _, f := range zr.File {
+		if _, ok := contents[f.Name]; ok {
+			t.Errorf("%s is in the zip twice", f.Name)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := io.ReadAll(rc)
+		rc.Close()
+		contents[f.Name] = string(b)
+		if strings.HasSuffix(f.Name, ".xml") {
+			if err := xml.Unmarshal(b, new(interface{})); err != nil {
+				t.Errorf("%s is not valid xml: %v", f.Name, err)
+			}
+		}
+	}
+
+	for _, name := range []string{
+		"imsmanifest.xml", "syllabus.html", "syllabus.md", "_course.yml", "intro/_lecture.yml",
+		"intro/intro.ipynb", "intro/notes.html", "intro/notes.md",
+	} {
+		if _, ok := contents[name]; !ok {
+			t.Errorf("missing %s", name)
+		}
+	}
+	for _, name := range []string{"intro/img/plot.png", "intro/data.csv"} {
+		if _, ok := contents[name]; !ok {
+			t.Errorf("missing %s, linked from notes.md", name)
+		}
+	}
+	if _, ok := contents["intro/answers.py"]; ok {
+		t.Error("intro/answers.py is in the cartridge, no block uses it")
+	}
+	if !strings.Contains(contents["intro/notes.html"], "<em>text</em>") {
+		t.Errorf("markdown was not rendered: %s", contents["intro/notes.html"])
+	}
+
+	manifest := contents["imsmanifest.xml"]
+	for _, want := range []string{
+		`<lomimscc:string language="en">Demo Course</lomimscc:string>`,
+		`<title>Syllabus</title>`,
+		`<title>Intro</title>`,
+		`type="imswl_xmlv1p3"`,
+		`type="imsqti_xmlv1p2/imscc_xmlv1p3/assessment"`,
+		`<file href="intro/img/plot.png">`,
+	} {
+		if !strings.Contains(manifest, want) {
+			t.Errorf("manifest does not contain %q", want)
+		}
+	}
+	// items are listed in the order of the lecture
+	order := []string{"<title>Notebook</title>", "<title>Notes</title>", "<title>Docs</title>", "<title>Welcome</title>", "<title>Check</title>"}
+	last := -1
+	for _, title := range order {
+		i := strings.Index(manifest, title)
+		if i < last {
+			t.Errorf("%s is out of order", title)
+		}
+		last = i
+	}
+
+	var video, quiz string
+	for name, content := range contents {
+		if strings.Contains(content, "youtube.com/watch?v=abc123") {
+			video = name
+		}
+		if strings.HasSuffix(name, "assessment.xml") {
+			quiz = content
+		}
+	}
+	if video == "" {
+		t.Error("video link not found")
+	}
+	for _, want := range []string{"cc.multiple_response.v0p1", "<not><varequal", "<fieldentry>2</fieldentry>"} {
+		if !strings.Contains(quiz, want) {
+			t.Errorf("assessment does not contain %q:\n%s", want, quiz)
+		}
+	}
+}
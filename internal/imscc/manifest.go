@@ -0,0 +1,102 @@
+package imscc
+
+import "encoding/xml"
+
+const (
+	cpNamespace        = "http://www.imsglobal.org/xsd/imsccv1p3/imscp_v1p1"
+	lomNamespace       = "http://ltsc.ieee.org/xsd/imsccv1p3/LOM/manifest"
+	manifestSchemaPath = "http://www.imsglobal.org/xsd/imsccv1p3/imscp_v1p1 http://www.imsglobal.org/profile/cc/ccv1p3/ccv1p3_imscp_v1p2_v1p0.xsd " +
+		"http://ltsc.ieee.org/xsd/imsccv1p3/LOM/manifest http://www.imsglobal.org/profile/cc/ccv1p3/LOM/ccv1p3_lommanifest_v1p0.xsd"
+)
+
+type webLinkURL struct {
+	Href   string `xml:"href,attr"`
+	Target string `xml:"target,attr"`
+}
+
+type webLink struct {
+	XMLName xml.Name   `xml:"webLink"`
+	Xmlns   string     `xml:"xmlns,attr"`
+	Title   string     `xml:"title"`
+	URL     webLinkURL `xml:"url"`
+}
+
+type lomString struct {
+	Language string `xml:"language,attr"`
+	Text     string `xml:",chardata"`
+}
+
+type manifestMetadata struct {
+	Schema        string    `xml:"schema"`
+	SchemaVersion string    `xml:"schemaversion"`
+	Title         lomString `xml:"lomimscc:lom>lomimscc:general>lomimscc:title>lomimscc:string"`
+}
+
+type manifestItem struct {
+	Identifier    string         `xml:"identifier,attr"`
+	IdentifierRef string         `xml:"identifierref,attr,omitempty"`
+	Title         string         `xml:"title,omitempty"`
+	Items         []manifestItem `xml:"item"`
+}
+
+type organization struct {
+	Identifier string       `xml:"identifier,attr"`
+	Structure  string       `xml:"structure,attr"`
+	Root       manifestItem `xml:"item"`
+}
+
+type manifestFile struct {
+	Href string `xml:"href,attr"`
+}
+
+type manifestResource struct {
+	Identifier string         `xml:"identifier,attr"`
+	Type       string         `xml:"type,attr"`
+	Href       string         `xml:"href,attr,omitempty"`
+	Files      []manifestFile `xml:"file"`
+}
+
+type manifest struct {
+	XMLName        xml.Name           `xml:"manifest"`
+	Xmlns          string             `xml:"xmlns,attr"`
+	Lom            string             `xml:"xmlns:lomimscc,attr"`
+	Xsi            string             `xml:"xmlns:xsi,attr"`
+	SchemaLocation string             `xml:"xsi:schemaLocation,attr"`
+	Identifier     string             `xml:"identifier,attr"`
+	Metadata       manifestMetadata   `xml:"metadata"`
+	Organizations  []organization     `xml:"organizations>organization"`
+	Resources      []manifestResource `xml:"resources>resource"`
+}
+
+func (c *Cartridge) manifest() manifest {
+	root := manifestItem{Identifier: "root"}
+	for _, m := range c.modules {
+		mi := manifestItem{Identifier: m.Identifier, Title: m.Title}
+		for _, it := range m.Items {
+			mi.Items = append(mi.Items, manifestItem{Identifier: it.Identifier, IdentifierRef: it.Resource, Title: it.Title})
+		}
+		root.Items = append(root.Items, mi)
+	}
+
+	m := manifest{
+		Xmlns:          cpNamespace,
+		Lom:            lomNamespace,
+		Xsi:            xsiNamespace,
+		SchemaLocation: manifestSchemaPath,
+		Identifier:     "manifest",
+		Metadata: manifestMetadata{
+			Schema:        "IMS Common Cartridge",
+			SchemaVersion: "1.3.0",
+			Title:         lomString{Language: "en", Text: c.Title},
+		},
+		Organizations: []organization{{Identifier: "organization", Structure: "rooted-hierarchy", Root: root}},
+	}
+	for _, r := range c.resources {
+		mr := manifestResource{Identifier: r.Identifier, Type: r.Type, Href: r.Href}
+		for _, f := range r.Files {
+			mr.Files = append(mr.Files, manifestFile{Href: f})
+		}
+		m.Resources = append(m.Resources, mr)
+	}
+	return m
+}
@@ -0,0 +1,47 @@
+package model
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// RemoveBlock removes the content block at position i and returns it
+func (lecture *LectureYaml) RemoveBlock(i int) ContentBlockYaml {
+	cb := lecture.ContentBlocks[i]
+	lecture.ContentBlocks = append(lecture.ContentBlocks[:i:i], lecture.ContentBlocks[i+1:]...)
+	return cb
+}
+
+// UsesFile reports whether a content block of the lecture refers to filename
+func (lecture *LectureYaml) UsesFile(filename string) bool {
+	for _, cb := range lecture.ContentBlocks {
+		if cb.Filename != "" && filepath.Clean(cb.Filename) == filepath.Clean(filename) {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate reports a block whose type is unknown or which is missing what its
+// type needs
+func (cb ContentBlockYaml) Validate() error {
+	if !contains(ContentBlockTypes[:], cb.Type) {
+		return fmt.Errorf("invalid type %q, must be one of %s", cb.Type, strings.Join(ContentBlockTypes[:], ", "))
+	}
+	switch cb.Type {
+	case "notebook", "markdown":
+		if cb.Filename == "" {
+			return fmt.Errorf("%s blocks need a filename", cb.Type)
+		}
+	case "link":
+		if cb.URL == "" {
+			return fmt.Errorf("link blocks need a url")
+		}
+	case "video":
+		if cb.URL == "" && cb.YoutubeVideoID == "" && cb.VimeoVideoID == "" {
+			return fmt.Errorf("video blocks need one of url, youtube_video_id or vimeo_video_id")
+		}
+	}
+	return nil
+}
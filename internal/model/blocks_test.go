@@ -0,0 +1,22 @@
+package model
+
+import "testing"
+
+func TestRemoveBlock(t *testing.T) {
+	lecture := &LectureYaml{ContentBlocks: []ContentBlockYaml{
+		{Title: "Notes", Filename: "notes.md"},
+		{Title: "Notes again", Filename: "./notes.md"},
+		{Title: "Video"},
+	}}
+	cb := lecture.RemoveBlock(0)
+	if cb.Title != "Notes" || len(lecture.ContentBlocks) != 2 || lecture.ContentBlocks[0].Title != "Notes again" {
+		t.Errorf("removed %+v, left %+v", cb, lecture.ContentBlocks)
+	}
+	if !lecture.UsesFile("notes.md") {
+		t.Error("notes.md is still used by a block")
+	}
+	lecture.RemoveBlock(0)
+	if lecture.UsesFile("notes.md") {
+		t.Error("notes.md is no longer used")
+	}
+}
@@ -0,0 +1,351 @@
+package model
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sglyon/jupyteach/internal/notebook"
+)
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Diagnostic is a single problem found while checking a course directory
+type Diagnostic struct {
+	Severity Severity
+	File     string
+	Line     int
+	Message  string
+}
+
+func (d Diagnostic) String() string {
+	if d.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s: %s", d.File, d.Line, d.Severity, d.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", d.File, d.Severity, d.Message)
+}
+
+// Report collects every diagnostic found by CheckCourse so they can all be
+// shown to the user at once instead of stopping at the first problem
+type Report struct {
+	Diagnostics []Diagnostic
+}
+
+func (r *Report) add(sev Severity, file string, line int, format string, args ...interface{}) {
+	r.Diagnostics = append(r.Diagnostics, Diagnostic{
+		Severity: sev,
+		File:     file,
+		Line:     line,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (r *Report) Errorf(file string, line int, format string, args ...interface{}) {
+	r.add(SeverityError, file, line, format, args...)
+}
+
+func (r *Report) Warnf(file string, line int, format string, args ...interface{}) {
+	r.add(SeverityWarning, file, line, format, args...)
+}
+
+func (r *Report) count(sev Severity) int {
+	n := 0
+	for _, d := range r.Diagnostics {
+		if d.Severity == sev {
+			n++
+		}
+	}
+	return n
+}
+
+func (r *Report) Errors() int   { return r.count(SeverityError) }
+func (r *Report) Warnings() int { return r.count(SeverityWarning) }
+func (r *Report) HasErrors() bool {
+	return r.Errors() > 0
+}
+
+func contains(list []string, x string) bool {
+	for _, v := range list {
+		if v == x {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckCourse validates `dirname/_course.yml` and every `_lecture.yml` it
+// references. Problems with the content are recorded in the returned Report;
+// the error is only non-nil if `_course.yml` itself cannot be read.
+func CheckCourse(dirname string) (*Report, error) {
+	report := &Report{}
+	courseFile := filepath.Join(dirname, "_course.yml")
+	course, err := ParseCourseYaml(dirname)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %w", courseFile, err)
+	}
+	pos := loadYamlPositions(courseFile)
+
+	if course.CourseType != "" && !contains(CourseTypes[:], course.CourseType) {
+		report.Errorf(
+			courseFile, pos.Line("course_type"),
+			"invalid course_type %q, must be one of %s",
+			course.CourseType, strings.Join(CourseTypes[:], ", "),
+		)
+	}
+
+	resolver, start, end := checkTimes(report, courseFile, pos, course)
+	floatingLine := 0
+
+	seen := make(map[string]int, len(course.Lectures))
+	for i, cl := range course.Lectures {
+		line := pos.Line("lectures", i, "directory")
+		if cl.Directory == "" {
+			report.Errorf(courseFile, pos.Line("lectures", i), "lecture %d has no directory", i+1)
+			continue
+		}
+		if first, ok := seen[cl.Directory]; ok {
+			report.Errorf(courseFile, line, "directory %s is listed more than once (first on line %d)", cl.Directory, first)
+			continue
+		}
+		seen[cl.Directory] = line
+
+		var available time.Time
+		if cl.AvailableAt != "" && resolver != nil {
+			atLine := pos.Line("lectures", i, "available_at")
+			res, err := resolver.Resolve(cl.AvailableAt)
+			if err != nil {
+				report.Errorf(courseFile, atLine, "available_at of %s: %s", cl.Directory, err)
+			} else {
+				available = res.Time
+				checkAvailableAt(report, courseFile, atLine, cl, res, start, end)
+				if !res.HasZone && course.Timezone == "" && floatingLine == 0 {
+					floatingLine = atLine
+				}
+			}
+		}
+
+		lectureDir := filepath.Join(dirname, cl.Directory)
+		if info, err := os.Stat(lectureDir); err != nil || !info.IsDir() {
+			report.Errorf(courseFile, line, "lecture directory %s does not exist", cl.Directory)
+			continue
+		}
+		checkLecture(report, course, cl, resolver, available, lectureDir)
+	}
+
+	if floatingLine > 0 {
+		report.Warnf(
+			courseFile, floatingLine,
+			"available_at times without a UTC offset depend on the time zone of the computer (here %s), set cli_timezone in _course.yml",
+			time.Now().Format("MST"),
+		)
+	}
+
+	return report, nil
+}
+
+// checkTimes reports an unknown cli_timezone or unreadable term dates. It
+// returns a resolver for the times of the lectures, nil when they can't be
+// resolved, and the bounds of the term.
+func checkTimes(report *Report, courseFile string, pos *yamlPositions, course *CourseYaml) (r *TimeResolver, start, end time.Time) {
+	r, err := course.lenientTimeResolver(time.Now())
+	if err != nil {
+		report.Errorf(courseFile, pos.Line("cli_timezone"), "%s", err)
+		return nil, start, end
+	}
+	// push sends the term dates as written, so one that can't be read only
+	// breaks the times counted from it
+	for _, d := range []struct{ field, value string }{{"start_date", course.StartDate}, {"end_date", course.EndDate}} {
+		if d.value == "" {
+			continue
+		}
+		if _, err := ParseTime(d.value, r.Location); err != nil {
+			report.Warnf(courseFile, pos.Line(d.field), "%s: %s", d.field, err)
+		}
+	}
+	start, end, _ = course.termBounds(r.Location)
+	return r, start, end
+}
+
+// checkAvailableAt warns about an available_at that is ambiguous or outside
+// of the term
+func checkAvailableAt(report *Report, courseFile string, line int, cl CourseLectureYaml, res ResolvedTime, start, end time.Time) {
+	if res.RelativeToNow {
+		report.Warnf(
+			courseFile, line,
+			"available_at %q of %s changes with the day it is read (today it resolves to %s), use a date or count from start instead",
+			cl.AvailableAt, cl.Directory, res.Time.Format("Mon 2006-01-02 15:04 MST"),
+		)
+	}
+	if res.Note != "" {
+		report.Warnf(courseFile, line, "available_at of %s is ambiguous: %s", cl.Directory, res.Note)
+	}
+	if !start.IsZero() && res.Time.Before(start) {
+		report.Warnf(courseFile, line, "%s becomes available at %s, before the course start_date", cl.Directory, res.Time.Format(time.RFC3339))
+	}
+	if !end.IsZero() && res.Time.After(end) {
+		report.Warnf(courseFile, line, "%s becomes available at %s, after the course end_date", cl.Directory, res.Time.Format(time.RFC3339))
+	}
+}
+
+func checkLecture(report *Report, course *CourseYaml, cl CourseLectureYaml, resolver *TimeResolver, available time.Time, lectureDir string) {
+	lectureFile := filepath.Join(lectureDir, "_lecture.yml")
+	lecture, err := ParseLectureYaml(lectureFile)
+	if err != nil {
+		report.Errorf(lectureFile, 0, "unable to parse lecture: %s", err)
+		return
+	}
+	pos := loadYamlPositions(lectureFile)
+
+	if lecture.Title == "" {
+		report.Errorf(lectureFile, pos.Line("title"), "lecture has no title")
+	} else if slug := course.SlugRules().Slugify(lecture.Title); slug != cl.Directory {
+		if legacySlugify(lecture.Title, course.Sep()) == cl.Directory {
+			report.Warnf(
+				lectureFile, pos.Line("title"),
+				"directory name %s follows the old naming rules, lecture title %q now gives %s",
+				cl.Directory, lecture.Title, slug,
+			)
+		} else {
+			report.Errorf(
+				lectureFile, pos.Line("title"),
+				"directory name %s does not match lecture title %q (expected %s)",
+				cl.Directory, lecture.Title, slug,
+			)
+		}
+	}
+
+	for i, cb := range lecture.ContentBlocks {
+		line := pos.Line("content_blocks", i)
+		name := cb.Title
+		if name == "" {
+			name = fmt.Sprintf("content block %d", i+1)
+		}
+
+		if !contains(ContentBlockTypes[:], cb.Type) {
+			report.Errorf(
+				lectureFile, pos.Line("content_blocks", i, "type"),
+				"%s has invalid type %q, must be one of %s",
+				name, cb.Type, strings.Join(ContentBlockTypes[:], ", "),
+			)
+		}
+
+		switch cb.Type {
+		case "notebook", "markdown":
+			if cb.Filename == "" {
+				report.Errorf(lectureFile, line, "%s block %s has no filename", cb.Type, name)
+			}
+		case "link":
+			if cb.URL == "" {
+				report.Errorf(lectureFile, line, "link block %s has no url", name)
+			}
+		case "video":
+			if cb.URL == "" && cb.YoutubeVideoID == "" && cb.VimeoVideoID == "" {
+				report.Errorf(lectureFile, line, "video block %s needs one of url, youtube_video_id or vimeo_video_id", name)
+			}
+		case "quiz":
+			if IsQuizMarkdown(cb.Filename) {
+				checkQuizMarkdown(report, lectureDir, name, cb)
+			} else {
+				checkQuiz(report, lectureFile, pos, i, name, cb.Quiz)
+			}
+		}
+
+		if cb.DueAt != "" && resolver != nil {
+			checkDueAt(report, lectureFile, pos.Line("content_blocks", i, "cli_due_at"), name, resolver, available, cb)
+		}
+
+		if cb.Filename != "" {
+			filename := filepath.Join(lectureDir, cb.Filename)
+			if _, err := os.Stat(filename); err != nil {
+				report.Errorf(
+					lectureFile, pos.Line("content_blocks", i, "filename"),
+					"file %s referenced by %s does not exist", cb.Filename, name,
+				)
+			} else if cb.Type == "notebook" {
+				checkNotebook(report, filename)
+			}
+		}
+	}
+}
+
+// checkDueAt reports a cli_due_at that can't be read, is ambiguous or comes
+// before the lecture is available
+func checkDueAt(report *Report, lectureFile string, line int, name string, r *TimeResolver, available time.Time, cb ContentBlockYaml) {
+	res, err := r.Resolve(cb.DueAt)
+	if err != nil {
+		report.Errorf(lectureFile, line, "cli_due_at of %s: %s", name, err)
+		return
+	}
+	if cb.Type != "quiz" && cb.NUploads == 0 {
+		report.Warnf(lectureFile, line, "cli_due_at of %s has no effect, only quizzes and blocks with uploads are due", name)
+	}
+	if res.RelativeToNow {
+		report.Warnf(
+			lectureFile, line,
+			"cli_due_at %q of %s changes with the day it is read (today it resolves to %s), use a date or count from start instead",
+			cb.DueAt, name, res.Time.Format("Mon 2006-01-02 15:04 MST"),
+		)
+	}
+	if res.Note != "" {
+		report.Warnf(lectureFile, line, "cli_due_at of %s is ambiguous: %s", name, res.Note)
+	}
+	if !available.IsZero() && res.Time.Before(available) {
+		report.Warnf(lectureFile, line, "%s is due before the lecture becomes available at %s", name, available.Format(time.RFC3339))
+	}
+}
+
+func checkNotebook(report *Report, filename string) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		report.Errorf(filename, 0, "unable to read notebook: %s", err)
+		return
+	}
+	nb, err := notebook.Parse(data)
+	if err != nil {
+		report.Errorf(filename, 0, "unable to parse notebook: %s", err)
+		return
+	}
+	for _, err := range nb.Validate() {
+		report.Errorf(filename, 0, "%s", err)
+	}
+}
+
+func checkQuiz(report *Report, lectureFile string, pos *yamlPositions, block int, name string, quiz Quiz) {
+	if len(quiz.Questions) == 0 {
+		report.Warnf(lectureFile, pos.Line("content_blocks", block), "quiz %s has no questions", name)
+	}
+	for j, q := range quiz.Questions {
+		for _, issue := range q.Validate() {
+			line := pos.Line("content_blocks", block, "quiz", "questions", j, issue.Field)
+			report.add(issue.Severity, lectureFile, line, "quiz %s question %d: %s", name, j+1, issue.Message)
+		}
+	}
+}
+
+func checkQuizMarkdown(report *Report, lectureDir, name string, cb ContentBlockYaml) {
+	quizFile := filepath.Join(lectureDir, cb.Filename)
+	if _, err := os.Stat(quizFile); err != nil {
+		// reported as a missing file by checkLecture
+		return
+	}
+	quiz, positions, err := cb.loadQuizMarkdown(lectureDir)
+	if err != nil {
+		report.Errorf(quizFile, 0, "unable to parse quiz: %s", err)
+		return
+	}
+	if len(quiz.Questions) == 0 {
+		report.Warnf(quizFile, 0, "quiz %s has no questions", name)
+	}
+	for j, q := range quiz.Questions {
+		for _, issue := range q.Validate() {
+			report.add(issue.Severity, quizFile, positions.Line(j, issue.Field), "quiz %s question %d: %s", name, j+1, issue.Message)
+		}
+	}
+}
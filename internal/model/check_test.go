@@ -0,0 +1,171 @@
+package model
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestCheckCourse(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"_course.yml": `course_type: weekly
+lectures:
+- directory: intro
+  available_at: "2024-01-10T09:00:00Z"
+- directory: wrong-name
+  available_at: whenever
+- directory: intro
+- directory: missing
+`,
+		"intro/_lecture.yml": `title: Intro
+content_blocks:
+- type: notebook
+  title: Notebook
+  filename: intro.ipynb
+- type: podcast
+  title: Podcast
+`,
+		"intro/intro.ipynb":       `{"cells": [], "metadata": {}, "nbformat": 4, "nbformat_minor": 5}`,
+		"wrong-name/_lecture.yml": "title: Right Name\ncontent_blocks:\n- type: markdown\n  filename: gone.md\n",
+	})
+
+	report, err := CheckCourse(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, d := range report.Diagnostics {
+		got = append(got, strings.TrimPrefix(d.String(), dir+string(filepath.Separator)))
+	}
+
+	expected := []string{
+		`_course.yml:1: error: invalid course_type "weekly"`,
+		`_course.yml:6: error: available_at of wrong-name: invalid time "whenever"`,
+		`wrong-name/_lecture.yml:1: error: directory name wrong-name does not match lecture title "Right Name"`,
+		`wrong-name/_lecture.yml:4: error: file gone.md referenced by content block 1 does not exist`,
+		`_course.yml:7: error: directory intro is listed more than once (first on line 3)`,
+		`_course.yml:8: error: lecture directory missing does not exist`,
+		`intro/_lecture.yml:6: error: Podcast has invalid type "podcast"`,
+	}
+	for _, want := range expected {
+		found := false
+		for _, g := range got {
+			if strings.HasPrefix(g, want) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("missing diagnostic %q in:\n%s", want, strings.Join(got, "\n"))
+		}
+	}
+	if len(got) != len(expected) {
+		t.Errorf("expected %d diagnostics, got %d:\n%s", len(expected), len(got), strings.Join(got, "\n"))
+	}
+	if !report.HasErrors() {
+		t.Error("expected report to have errors")
+	}
+}
+
+func TestCheckCourseTimes(t *testing.T) {
+	dir := t.TempDir()
+	lectures := `start_date: 2024-09-02
+lectures:
+- directory: a
+  available_at: start+1w 9am
+- directory: b
+  available_at: next tue 9am
+- directory: c
+  available_at: 2024-08-26 09:00
+- directory: d
+  available_at: 2024-11-03 1:30am
+`
+	writeFiles(t, dir, map[string]string{
+		"_course.yml":    lectures,
+		"a/_lecture.yml": "title: A\ncontent_blocks:\n- type: markdown\n  title: HW\n  filename: hw.md\n  n_uploads: 1\n  cli_due_at: start+1w 8am\n- type: markdown\n  title: Essay\n  filename: hw.md\n  n_uploads: 1\n  cli_due_at: soonish\n",
+		"a/hw.md":        "# Homework\n",
+		"b/_lecture.yml": "title: B\ncontent_blocks: []\n",
+		"c/_lecture.yml": "title: C\ncontent_blocks: []\n",
+		"d/_lecture.yml": "title: D\ncontent_blocks: []\n",
+	})
+
+	check := func(expected []string) {
+		t.Helper()
+		report, err := CheckCourse(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, d := range report.Diagnostics {
+			got = append(got, strings.TrimPrefix(d.String(), dir+string(filepath.Separator)))
+		}
+		all := strings.Join(got, "\n")
+		for _, want := range expected {
+			if !strings.Contains(all, want) {
+				t.Errorf("missing diagnostic %q in:\n%s", want, all)
+			}
+		}
+		if len(got) != len(expected) {
+			t.Errorf("expected %d diagnostics, got %d:\n%s", len(expected), len(got), all)
+		}
+	}
+
+	check([]string{
+		`_course.yml:4: warning: available_at times without a UTC offset depend on the time zone of the computer`,
+		`_course.yml:6: warning: available_at "next tue 9am" of b changes with the day it is read`,
+		`_course.yml:8: warning: c becomes available at 2024-08-26T09:00:00`,
+		`a/_lecture.yml:7: warning: HW is due before the lecture becomes available at 2024-09-09T09:00:00`,
+		`a/_lecture.yml:12: error: cli_due_at of Essay: invalid time "soonish"`,
+	})
+
+	writeFiles(t, dir, map[string]string{"_course.yml": "cli_timezone: America/New_York\n" + lectures})
+	check([]string{
+		`_course.yml:7: warning: available_at "next tue 9am" of b changes with the day it is read`,
+		`_course.yml:9: warning: c becomes available at 2024-08-26T09:00:00-04:00, before the course start_date`,
+		`_course.yml:11: warning: available_at of d is ambiguous: 2024-11-03 01:30 happens twice in America/New_York`,
+		`a/_lecture.yml:7: warning: HW is due before the lecture becomes available at 2024-09-09T09:00:00-04:00`,
+		`a/_lecture.yml:12: error: cli_due_at of Essay: invalid time "soonish"`,
+	})
+
+	writeFiles(t, dir, map[string]string{"_course.yml": "start_date: fall 2024\nlectures: []\n"})
+	check([]string{`_course.yml:1: warning: start_date: invalid time "fall 2024"`})
+
+	writeFiles(t, dir, map[string]string{"_course.yml": "cli_timezone: Nowhere/Special\nlectures: []\n"})
+	check([]string{`_course.yml:1: error: unknown timezone "Nowhere/Special"`})
+}
+
+func TestCheckCourseSlugRules(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"_course.yml":              "lectures:\n- directory: what's-new?\n- directory: whats-new\n",
+		"what's-new?/_lecture.yml": "title: What's New?\ncontent_blocks: []\n",
+		"whats-new/_lecture.yml":   "title: What's New?\ncontent_blocks: []\n",
+	})
+	report, err := CheckCourse(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Diagnostics) != 1 {
+		t.Fatalf("expected 1 diagnostic, got %v", report.Diagnostics)
+	}
+	want := `warning: directory name what's-new? follows the old naming rules, lecture title "What's New?" now gives whats-new`
+	if got := report.Diagnostics[0].String(); !strings.Contains(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
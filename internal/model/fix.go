@@ -0,0 +1,449 @@
+package model
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// Repair is a problem found by PlanFixes and what is done about it
+type Repair struct {
+	// File is the file or directory with the problem, relative to the course
+	File    string
+	Problem string
+	Fix     string
+}
+
+// FixPlan holds the repairs PlanFixes found for a course. Nothing is changed
+// on disk until Apply is called.
+type FixPlan struct {
+	// Repairs are made by Apply
+	Repairs []Repair
+
+	// Skipped are problems that can't be repaired mechanically, Fix says why
+	Skipped []Repair
+
+	course      *CourseYaml
+	courseFile  yamlFile
+	writeCourse bool
+	lectures    []*lectureFix
+	force       bool
+}
+
+// FixOptions controls what PlanFixes may do
+type FixOptions struct {
+	// ForceReformat allows repairs that rewrite yaml files with comments or
+	// fields jupyteach doesn't read, which are lost in the rewrite
+	ForceReformat bool
+}
+
+// lectureFix is what Apply does to one lecture
+type lectureFix struct {
+	lecture  *LectureYaml
+	file     yamlFile
+	from, to string
+	write    bool
+}
+
+// yamlFile is what is needed to rewrite a yaml file the way jupyteach writes
+// it
+type yamlFile struct {
+	// header is the block of comments at the top of the file, such as the
+	// yaml-language-server schema line, which is kept on rewrite
+	header []byte
+
+	// lossy says what rewriting the file would lose, if anything
+	lossy string
+
+	// canonical is whether the file is already written the way jupyteach
+	// writes it
+	canonical bool
+}
+
+// PlanFixes finds the problems of the course in dirname that can be repaired
+// without a person deciding anything: lecture directories that don't match
+// their title, lectures in _course.yml whose directory is gone, lecture
+// directories missing from _course.yml, content block files moved elsewhere
+// in their lecture, and yaml files not formatted the way jupyteach writes
+// them. Repairs that would rewrite a file with comments or unknown fields are
+// skipped unless opts.ForceReformat is set.
+func PlanFixes(dirname string, opts FixOptions) (*FixPlan, error) {
+	courseFile := filepath.Join(dirname, "_course.yml")
+	course := &CourseYaml{}
+	cf, err := readYamlFile(courseFile, course)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %w", courseFile, err)
+	}
+	p := &FixPlan{course: course, courseFile: cf, force: opts.ForceReformat}
+	rules := course.SlugRules()
+
+	unlisted, err := p.unlistedLectures(dirname)
+	if err != nil {
+		return nil, err
+	}
+
+	// lectures whose directory is gone point at the unlisted directory that
+	// holds them, when there is one, and are dropped otherwise
+	lectures := make([]CourseLectureYaml, 0, len(course.Lectures))
+	for _, cl := range course.Lectures {
+		if cl.Directory == "" || isDir(filepath.Join(dirname, cl.Directory)) {
+			lectures = append(lectures, cl)
+			continue
+		}
+		problem := fmt.Sprintf("lecture directory %s does not exist", cl.Directory)
+		i, why := matchLecture(unlisted, cl, rules)
+		if i < 0 {
+			if p.plan("_course.yml", p.courseFile, Repair{"_course.yml", problem, "remove the lecture from _course.yml"}) {
+				p.writeCourse = true
+			} else {
+				lectures = append(lectures, cl)
+			}
+			continue
+		}
+		if p.plan("_course.yml", p.courseFile, Repair{"_course.yml", problem, fmt.Sprintf("point it at %s, %s", unlisted[i].dir, why)}) {
+			p.writeCourse = true
+			cl.Directory = unlisted[i].dir
+		}
+		lectures = append(lectures, cl)
+		unlisted = append(unlisted[:i], unlisted[i+1:]...)
+	}
+	for _, u := range unlisted {
+		if p.plan("_course.yml", p.courseFile, Repair{u.dir, "lecture directory is not listed in _course.yml", "add it after the other lectures"}) {
+			p.writeCourse = true
+			lectures = append(lectures, CourseLectureYaml{Directory: u.dir})
+		}
+	}
+	course.Lectures = lectures
+
+	claimed := make(map[string]bool, len(course.Lectures))
+	for _, cl := range course.Lectures {
+		claimed[cl.Directory] = true
+	}
+	seen := make(map[string]bool, len(course.Lectures))
+	for i, cl := range course.Lectures {
+		// a directory still missing was skipped above
+		if cl.Directory == "" || seen[cl.Directory] || !isDir(filepath.Join(dirname, cl.Directory)) {
+			continue
+		}
+		seen[cl.Directory] = true
+		name := filepath.ToSlash(filepath.Join(cl.Directory, "_lecture.yml"))
+		lecture := &LectureYaml{}
+		lf, err := readYamlFile(filepath.Join(dirname, cl.Directory, "_lecture.yml"), lecture)
+		if err != nil {
+			p.Skipped = append(p.Skipped, Repair{name, fmt.Sprintf("unable to parse lecture: %s", err), "fix the file by hand"})
+			continue
+		}
+		fix := &lectureFix{lecture: lecture, file: lf, from: cl.Directory, to: cl.Directory}
+		p.lectures = append(p.lectures, fix)
+
+		p.fixFilenames(dirname, name, fix)
+		if to := p.fixDirectory(dirname, rules, fix, claimed); to != cl.Directory {
+			course.Lectures[i].Directory = to
+			p.writeCourse = true
+		}
+		fix.write = p.fixFormat(name, fix.file, fix.write)
+	}
+	p.writeCourse = p.fixFormat("_course.yml", p.courseFile, p.writeCourse)
+
+	return p, nil
+}
+
+// Apply makes the repairs of the plan, renaming lecture directories with
+// move, which gets paths relative to dirname
+func (p *FixPlan) Apply(dirname string, move func(from, to string) error) error {
+	for _, fix := range p.lectures {
+		if fix.from != fix.to {
+			if err := move(fix.from, fix.to); err != nil {
+				return err
+			}
+		}
+		if fix.write {
+			if err := writeYamlFile(filepath.Join(dirname, fix.to, "_lecture.yml"), fix.file.header, fix.lecture); err != nil {
+				return err
+			}
+		}
+	}
+	if p.writeCourse {
+		return writeYamlFile(filepath.Join(dirname, "_course.yml"), p.courseFile.header, p.course)
+	}
+	return nil
+}
+
+// unlistedLecture is a lecture directory on disk missing from _course.yml
+type unlistedLecture struct {
+	dir     string
+	lecture *LectureYaml
+}
+
+// unlistedLectures returns the directories of dirname with a _lecture.yml
+// that _course.yml doesn't list
+func (p *FixPlan) unlistedLectures(dirname string) ([]unlistedLecture, error) {
+	listed := make(map[string]bool, len(p.course.Lectures))
+	for _, cl := range p.course.Lectures {
+		listed[filepath.Clean(cl.Directory)] = true
+	}
+	entries, err := os.ReadDir(dirname)
+	if err != nil {
+		return nil, err
+	}
+	var out []unlistedLecture
+	for _, e := range entries {
+		if !e.IsDir() || strings.HasPrefix(e.Name(), ".") || listed[e.Name()] {
+			continue
+		}
+		lectureFile := filepath.Join(dirname, e.Name(), "_lecture.yml")
+		if _, err := os.Stat(lectureFile); err != nil {
+			continue
+		}
+		lecture, err := ParseLectureYaml(lectureFile)
+		if err != nil {
+			p.Skipped = append(p.Skipped, Repair{
+				e.Name(), "lecture directory is not listed in _course.yml",
+				fmt.Sprintf("its _lecture.yml can't be parsed: %s", err),
+			})
+			continue
+		}
+		out = append(out, unlistedLecture{e.Name(), lecture})
+	}
+	return out, nil
+}
+
+// matchLecture finds the unlisted directory holding the lecture cl, by its
+// lecture_id or by a title that gives the directory cl names. It returns -1
+// when there is none, and otherwise how the match was made.
+func matchLecture(unlisted []unlistedLecture, cl CourseLectureYaml, rules SlugRules) (int, string) {
+	if cl.LectureID != 0 {
+		for i, u := range unlisted {
+			if u.lecture.LectureID == cl.LectureID {
+				return i, fmt.Sprintf("which has lecture_id %d", cl.LectureID)
+			}
+		}
+	}
+	for i, u := range unlisted {
+		title := u.lecture.Title
+		if title != "" && (rules.Slugify(title) == cl.Directory || legacySlugify(title, rules.Separator) == cl.Directory) {
+			return i, fmt.Sprintf("whose title %q gives %s", title, cl.Directory)
+		}
+	}
+	return -1, ""
+}
+
+// fixFilenames points blocks whose file is missing at the only file with the
+// same name elsewhere in the lecture
+func (p *FixPlan) fixFilenames(dirname, name string, fix *lectureFix) {
+	lectureDir := filepath.Join(dirname, fix.from)
+	for i := range fix.lecture.ContentBlocks {
+		cb := &fix.lecture.ContentBlocks[i]
+		if cb.Filename == "" {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(lectureDir, cb.Filename)); err == nil {
+			continue
+		}
+		block := cb.Title
+		if block == "" {
+			block = fmt.Sprintf("content block %d", i+1)
+		}
+		problem := fmt.Sprintf("file %s referenced by %s does not exist", cb.Filename, block)
+
+		found := findFiles(lectureDir, filepath.Base(cb.Filename))
+		switch len(found) {
+		case 0:
+			p.Skipped = append(p.Skipped, Repair{name, problem, fmt.Sprintf("no file named %s in the lecture", filepath.Base(cb.Filename))})
+		case 1:
+			if p.plan(name, fix.file, Repair{name, problem, fmt.Sprintf("point it at %s", found[0])}) {
+				cb.Filename = found[0]
+				fix.write = true
+			}
+		default:
+			p.Skipped = append(p.Skipped, Repair{name, problem, fmt.Sprintf("more than one candidate: %s", strings.Join(found, ", "))})
+		}
+	}
+}
+
+// findFiles returns the paths, relative to dir, of the files called base in
+// dir and its subdirectories, leaving out hidden directories
+func findFiles(dir, base string) []string {
+	var found []string
+	filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if p != dir && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Name() == base {
+			if rel, err := filepath.Rel(dir, p); err == nil {
+				found = append(found, filepath.ToSlash(rel))
+			}
+		}
+		return nil
+	})
+	sort.Strings(found)
+	return found
+}
+
+// fixDirectory plans moving the lecture to the directory named after its
+// title and returns the directory the lecture ends up in
+func (p *FixPlan) fixDirectory(dirname string, rules SlugRules, fix *lectureFix, claimed map[string]bool) string {
+	title := fix.lecture.Title
+	if title == "" {
+		p.Skipped = append(p.Skipped, Repair{fix.from, "lecture has no title", "add a title to name the directory after"})
+		return fix.from
+	}
+	slug := rules.Slugify(title)
+	if slug == fix.from {
+		return fix.from
+	}
+	problem := fmt.Sprintf("directory name does not match lecture title %q", title)
+	if slug == "" {
+		p.Skipped = append(p.Skipped, Repair{fix.from, problem, "the title gives an empty directory name"})
+		return fix.from
+	}
+	if _, err := os.Stat(filepath.Join(dirname, slug)); err == nil || claimed[slug] {
+		p.Skipped = append(p.Skipped, Repair{fix.from, problem, fmt.Sprintf("%s is already taken", slug)})
+		return fix.from
+	}
+	// the new directory has to be written to _course.yml
+	if !p.plan("_course.yml", p.courseFile, Repair{fix.from, problem, fmt.Sprintf("move it to %s", slug)}) {
+		return fix.from
+	}
+	claimed[slug] = true
+	fix.to = slug
+	return slug
+}
+
+// plan adds r, a repair that rewrites the yaml file name, to the repairs. When
+// the rewrite would lose comments or unknown fields of f it is skipped instead,
+// unless reformatting is forced. It reports whether r is made.
+func (p *FixPlan) plan(name string, f yamlFile, r Repair) bool {
+	if f.lossy != "" && !p.force {
+		r.Fix = fmt.Sprintf("would %s, but %s %s that the rewrite loses; use --force-reformat", r.Fix, name, f.lossy)
+		p.Skipped = append(p.Skipped, r)
+		return false
+	}
+	p.Repairs = append(p.Repairs, r)
+	return true
+}
+
+// fixFormat plans rewriting the file name when it isn't formatted the way
+// jupyteach writes it, unless that would lose something. A file written for
+// other repairs, which only happens with reformatting forced, is listed with
+// what it loses. It returns whether the file is written.
+func (p *FixPlan) fixFormat(name string, f yamlFile, write bool) bool {
+	switch {
+	case write && f.lossy != "":
+		p.Repairs = append(p.Repairs, Repair{name, fmt.Sprintf("file %s", f.lossy), "lost when the file is rewritten for the repairs above"})
+	case write || f.canonical:
+	case f.lossy != "":
+		p.Skipped = append(p.Skipped, Repair{name, "not formatted the way jupyteach writes it", fmt.Sprintf("left as is because it %s", f.lossy)})
+	default:
+		p.Repairs = append(p.Repairs, Repair{name, "not formatted the way jupyteach writes it", "reformat it"})
+		write = true
+	}
+	return write
+}
+
+// readYamlFile parses the yaml file at path into v, a pointer to a struct,
+// and tells how it compares with the way jupyteach writes it
+func readYamlFile(path string, v interface{}) (yamlFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return yamlFile{}, err
+	}
+	if err := yaml.Unmarshal(data, v); err != nil {
+		return yamlFile{}, err
+	}
+
+	f := yamlFile{header: yamlHeader(data)}
+	body := data[len(f.header):]
+	strict := reflect.New(reflect.TypeOf(v).Elem()).Interface()
+	switch {
+	case yaml.UnmarshalStrict(data, strict) != nil:
+		f.lossy = "has fields jupyteach doesn't read"
+	case hasYamlComments(body):
+		f.lossy = "has comments"
+	}
+	out, err := yaml.Marshal(v)
+	if err != nil {
+		return yamlFile{}, err
+	}
+	f.canonical = bytes.Equal(body, out)
+	return f, nil
+}
+
+// writeYamlFile writes v to path the way jupyteach writes yaml, after header
+func writeYamlFile(path string, header []byte, v interface{}) error {
+	out, err := yaml.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(append([]byte{}, header...), out...), 0o644)
+}
+
+// yamlHeader returns the comment lines, and blank lines between them, at the
+// top of a yaml file
+func yamlHeader(data []byte) []byte {
+	end := 0
+	for i := 0; i < len(data); {
+		next := bytes.IndexByte(data[i:], '\n')
+		if next < 0 {
+			next = len(data)
+		} else {
+			next += i + 1
+		}
+		line := bytes.TrimSpace(data[i:next])
+		if len(line) > 0 && line[0] != '#' {
+			break
+		}
+		if len(line) > 0 {
+			end = next
+		}
+		i = next
+	}
+	// blank lines right after the comments belong to the header too
+	for end < len(data) && (data[end] == '\n' || data[end] == '\r') {
+		end++
+	}
+	if end == 0 || !bytes.Contains(data[:end], []byte("#")) {
+		return nil
+	}
+	return data[:end]
+}
+
+// hasYamlComments reports whether the yaml document has any comments
+func hasYamlComments(data []byte) bool {
+	var doc yamlv3.Node
+	if err := yamlv3.Unmarshal(data, &doc); err != nil {
+		return false
+	}
+	var walk func(n *yamlv3.Node) bool
+	walk = func(n *yamlv3.Node) bool {
+		if n.HeadComment != "" || n.LineComment != "" || n.FootComment != "" {
+			return true
+		}
+		for _, c := range n.Content {
+			if walk(c) {
+				return true
+			}
+		}
+		return false
+	}
+	return walk(&doc)
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
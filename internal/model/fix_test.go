@@ -0,0 +1,149 @@
+package model
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPlanFixes(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"_course.yml": `# yaml-language-server: $schema=course.json
+
+slug: econ-101
+lectures:
+- directory: intro
+  lecture_id: 12
+- directory: gone
+- directory: old-name
+  lecture_id: 13
+`,
+		"intro/_lecture.yml": `title: Intro
+content_blocks:
+- {type: notebook, title: NB, filename: nb.ipynb}
+- {type: markdown, title: Notes, filename: notes.md}
+`,
+		"intro/notebooks/nb.ipynb": "{}",
+		"moved/_lecture.yml":       "title: Old Name\nlecture_id: 13\n",
+		"Extra/_lecture.yml":       "title: Extra\n# kept\n",
+	})
+
+	plan, err := PlanFixes(dir, FixOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fixes []string
+	for _, r := range plan.Repairs {
+		fixes = append(fixes, r.File+": "+r.Fix)
+	}
+	want := []string{
+		"_course.yml: remove the lecture from _course.yml",
+		"_course.yml: point it at moved, which has lecture_id 13",
+		"Extra: add it after the other lectures",
+		"intro/_lecture.yml: point it at notebooks/nb.ipynb",
+		"moved: move it to old-name",
+		"moved/_lecture.yml: reformat it",
+		"Extra: move it to extra",
+	}
+	if strings.Join(fixes, "\n") != strings.Join(want, "\n") {
+		t.Errorf("repairs are\n%s\nwant\n%s", strings.Join(fixes, "\n"), strings.Join(want, "\n"))
+	}
+	// the comment in Extra/_lecture.yml keeps it from being reformatted
+	if len(plan.Skipped) != 2 || !strings.Contains(plan.Skipped[0].Problem, "notes.md") || plan.Skipped[1].File != "Extra/_lecture.yml" {
+		t.Errorf("skipped %+v", plan.Skipped)
+	}
+
+	move := func(from, to string) error {
+		return os.Rename(filepath.Join(dir, from), filepath.Join(dir, to))
+	}
+	if err := plan.Apply(dir, move); err != nil {
+		t.Fatal(err)
+	}
+	course, err := ParseCourseYaml(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dirs []string
+	for _, cl := range course.Lectures {
+		dirs = append(dirs, cl.Directory)
+	}
+	if got := strings.Join(dirs, " "); got != "intro old-name extra" {
+		t.Errorf("lectures are %s", got)
+	}
+	if course.Lectures[1].LectureID != 13 {
+		t.Error("the lecture lost its id")
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "_course.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "# yaml-language-server: $schema=course.json\n\n") {
+		t.Errorf("the header comment was lost:\n%s", data)
+	}
+	lecture, err := ParseLectureYaml(filepath.Join(dir, "intro", "_lecture.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lecture.ContentBlocks[0].Filename != "notebooks/nb.ipynb" {
+		t.Errorf("filename is %s", lecture.ContentBlocks[0].Filename)
+	}
+
+	plan, err = PlanFixes(dir, FixOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Repairs) != 0 {
+		t.Errorf("repairs left after applying: %+v", plan.Repairs)
+	}
+}
+
+func TestPlanFixesLossy(t *testing.T) {
+	dir := t.TempDir()
+	course := "slug: econ-101\nlectures:\n- directory: intro # first week\n- directory: gone\n"
+	writeFiles(t, dir, map[string]string{
+		"_course.yml":         course,
+		"intro/_lecture.yml":  "title: Intro\nextra: kept\ncontent_blocks:\n- {type: markdown, title: Notes, filename: notes.md}\n",
+		"intro/docs/notes.md": "# Notes\n",
+	})
+
+	// without --force-reformat nothing that loses the comment or the unknown
+	// field is done
+	plan, err := PlanFixes(dir, FixOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Repairs) != 0 {
+		t.Errorf("repairs %+v", plan.Repairs)
+	}
+	if len(plan.Skipped) != 4 ||
+		plan.Skipped[0].Fix != "would remove the lecture from _course.yml, but _course.yml has comments that the rewrite loses; use --force-reformat" ||
+		!strings.Contains(plan.Skipped[1].Fix, "intro/_lecture.yml has fields jupyteach doesn't read") {
+		t.Errorf("skipped %+v", plan.Skipped)
+	}
+	if err := plan.Apply(dir, nil); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "_course.yml")); string(data) != course {
+		t.Errorf("_course.yml was rewritten:\n%s", data)
+	}
+
+	plan, err = PlanFixes(dir, FixOptions{ForceReformat: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fixes []string
+	for _, r := range plan.Repairs {
+		fixes = append(fixes, r.File+": "+r.Fix)
+	}
+	want := []string{
+		"_course.yml: remove the lecture from _course.yml",
+		"intro/_lecture.yml: point it at docs/notes.md",
+		"intro/_lecture.yml: lost when the file is rewritten for the repairs above",
+		"_course.yml: lost when the file is rewritten for the repairs above",
+	}
+	if strings.Join(fixes, "\n") != strings.Join(want, "\n") {
+		t.Errorf("repairs are\n%s\nwant\n%s", strings.Join(fixes, "\n"), strings.Join(want, "\n"))
+	}
+}
@@ -0,0 +1,223 @@
+package model
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ClearServerIDs removes the identifiers the server assigned to the course
+// and its lectures, and the commit shas of the last push, so the course can
+// be pushed as a new one
+func (c *CourseYaml) ClearServerIDs() {
+	c.ID = 0
+	c.LastCommitSHA = ""
+	c.CLICommitSHA = ""
+	c.SyncStatusUpdateTimestamp = ""
+	for i := range c.Lectures {
+		c.Lectures[i].CourseLectureID = 0
+		c.Lectures[i].LectureID = 0
+	}
+}
+
+// ClearServerIDs removes the identifiers the server assigned to the lecture,
+// its content blocks, quizzes and questions. It reports whether there were
+// any.
+func (lecture *LectureYaml) ClearServerIDs() bool {
+	cleared := lecture.CourseLectureID != 0 || lecture.LectureID != 0
+	lecture.CourseLectureID = 0
+	lecture.LectureID = 0
+	for i := range lecture.ContentBlocks {
+		cb := &lecture.ContentBlocks[i]
+		cleared = cleared || cb.ContentBlockID != 0 || cb.LectureContentID != 0 || cb.Quiz.QuizID != 0
+		cb.ContentBlockID = 0
+		cb.LectureContentID = 0
+		cb.Quiz.QuizID = 0
+		for j := range cb.Quiz.Questions {
+			cleared = cleared || cb.Quiz.Questions[j].ID != 0
+			cb.Quiz.Questions[j].ID = 0
+		}
+	}
+	return cleared
+}
+
+// WriteYaml writes the lecture to the `_lecture.yml` file at path
+func (lecture *LectureYaml) WriteYaml(path string) error {
+	content, err := yaml.Marshal(lecture)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0o644)
+}
+
+// StripQuizMarkdownIDs removes the quiz_id of the front matter and the id of
+// every question from a markdown quiz, leaving the rest of the file as it is
+func StripQuizMarkdownIDs(data []byte) []byte {
+	lines := strings.SplitAfter(string(data), "\n")
+	var out []string
+
+	i := 0
+	if len(lines) > 0 && strings.TrimSpace(lines[0]) == "---" {
+		var front []string
+		end := -1
+		for j := 1; j < len(lines); j++ {
+			if strings.TrimSpace(lines[j]) == "---" {
+				end = j
+				break
+			}
+			if !strings.HasPrefix(strings.TrimSpace(lines[j]), "quiz_id:") {
+				front = append(front, lines[j])
+			}
+		}
+		if end > 0 {
+			if len(front) > 0 {
+				out = append(out, lines[0])
+				out = append(out, front...)
+				out = append(out, lines[end])
+			} else if end+1 < len(lines) && strings.TrimSpace(lines[end+1]) == "" {
+				end++
+			}
+			i = end + 1
+		}
+	}
+
+	// the attributes of a question are the key: value lines right after its
+	// heading, so only those are looked at
+	inAttributes, seenAttribute, inFence := false, false, ""
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		if m := quizMarkdownFence.FindStringSubmatch(line); m != nil {
+			switch {
+			case inFence == "":
+				inFence = m[1]
+			case strings.HasPrefix(m[1], inFence) && m[2] == "":
+				inFence = ""
+			}
+		}
+		switch {
+		case inFence != "":
+			inAttributes = false
+		case strings.HasPrefix(line, "## "):
+			inAttributes, seenAttribute = true, false
+		case inAttributes && trimmed == "":
+			inAttributes = !seenAttribute
+		case inAttributes:
+			m := quizMarkdownAttribute.FindStringSubmatch(trimmed)
+			if m == nil {
+				inAttributes = false
+				break
+			}
+			seenAttribute = true
+			if m[1] == "id" {
+				continue
+			}
+		}
+		out = append(out, line)
+	}
+	return []byte(strings.Join(out, ""))
+}
+
+// Fork copies the course in dirname to newDir, which must not exist, as a new
+// course with the given slug. The git history is left behind and every
+// identifier assigned by the server is removed, from `_course.yml`, the
+// `_lecture.yml` files and markdown quizzes, so pushing the copy can't touch
+// the records of the original course.
+func Fork(dirname, newDir, slug string) (*CourseYaml, error) {
+	if _, err := os.Stat(newDir); err == nil {
+		return nil, fmt.Errorf("%s already exists", newDir)
+	}
+	src, err := filepath.Abs(dirname)
+	if err != nil {
+		return nil, err
+	}
+	dst, err := filepath.Abs(newDir)
+	if err != nil {
+		return nil, err
+	}
+	if dst == src || strings.HasPrefix(dst, src+string(filepath.Separator)) {
+		return nil, fmt.Errorf("%s is inside the course being forked", newDir)
+	}
+
+	if err := copyTree(src, dst); err != nil {
+		return nil, err
+	}
+
+	course, err := ParseCourseYaml(dst)
+	if err != nil {
+		return nil, err
+	}
+	course.Slug = slug
+	course.ClearServerIDs()
+
+	for _, cl := range course.Lectures {
+		lectureDir := filepath.Join(dst, cl.Directory)
+		lectureFile := filepath.Join(lectureDir, "_lecture.yml")
+		lecture, err := ParseLectureYaml(lectureFile)
+		if err != nil {
+			return nil, err
+		}
+		if lecture.ClearServerIDs() {
+			if err := lecture.WriteYaml(lectureFile); err != nil {
+				return nil, err
+			}
+		}
+		for _, cb := range lecture.ContentBlocks {
+			if cb.Type != "quiz" || !IsQuizMarkdown(cb.Filename) {
+				continue
+			}
+			quizFile := filepath.Join(lectureDir, cb.Filename)
+			data, err := os.ReadFile(quizFile)
+			if err != nil {
+				return nil, err
+			}
+			if stripped := StripQuizMarkdownIDs(data); !bytes.Equal(stripped, data) {
+				if err := os.WriteFile(quizFile, stripped, 0o644); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	if err := course.WriteYaml(dst); err != nil {
+		return nil, err
+	}
+	return course, nil
+}
+
+// copyTree copies the files of src to dst, leaving out the git repository
+func copyTree(src, dst string) error {
+	return filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		target := filepath.Join(dst, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0o755)
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, content, info.Mode().Perm())
+	})
+}
@@ -0,0 +1,88 @@
+package model
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFork(t *testing.T) {
+	root := t.TempDir()
+	src := filepath.Join(root, "econ-101")
+	writeFiles(t, src, map[string]string{
+		"_course.yml": `id: 7
+slug: econ-101
+last_commit_sha: abc123
+cli_commit_sha: def456
+lectures:
+- directory: intro
+  course_lecture_id: 11
+  lecture_id: 12
+`,
+		"intro/_lecture.yml": `title: Intro
+lecture_id: 12
+content_blocks:
+- type: quiz
+  title: Inline
+  content_block_id: 21
+  lecture_content_id: 22
+  quiz:
+    quiz_id: 31
+    questions:
+    - id: 41
+      question_type: single_selection
+      question_text: Pick
+      options: ["a", "b"]
+      solution: a
+- type: quiz
+  title: Markdown
+  filename: quiz.md
+`,
+		"intro/quiz.md": "---\nquiz_id: 32\n---\n\n## Question 1\n\ntype: code\nid: 42\npoints: 1\n\nWrite it\n\n```python solution\nid: 1\n```\n",
+		".git/HEAD":     "ref: refs/heads/main\n",
+	})
+
+	dst := filepath.Join(root, "econ-101-spring")
+	course, err := Fork(src, dst, "econ-101-spring")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if course.Slug != "econ-101-spring" || course.ID != 0 || course.LastCommitSHA != "" || course.CLICommitSHA != "" || course.Lectures[0].LectureID != 0 {
+		t.Errorf("ids left in course: %+v", course)
+	}
+	if _, err := os.Stat(filepath.Join(dst, ".git")); !os.IsNotExist(err) {
+		t.Error("the git repository was copied")
+	}
+
+	lecture, err := ParseLectureYaml(filepath.Join(dst, "intro", "_lecture.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lecture.ClearServerIDs() {
+		t.Errorf("ids left in lecture: %+v", lecture)
+	}
+	quiz, err := os.ReadFile(filepath.Join(dst, "intro", "quiz.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "## Question 1\n\ntype: code\npoints: 1\n\nWrite it\n\n```python solution\nid: 1\n```\n"
+	if string(quiz) != want {
+		t.Errorf("quiz.md is\n%s\nwant\n%s", quiz, want)
+	}
+
+	original, err := os.ReadFile(filepath.Join(src, "_course.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(original), "id: 7") {
+		t.Error("the original course was changed")
+	}
+
+	if _, err := Fork(src, dst, "again"); err == nil {
+		t.Error("expected an error forking into an existing directory")
+	}
+	if _, err := Fork(src, filepath.Join(src, "copy"), "inside"); err == nil {
+		t.Error("expected an error forking into the course itself")
+	}
+}
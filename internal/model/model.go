@@ -10,6 +10,7 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/sglyon/jupyteach/internal/notebook"
 	"gopkg.in/yaml.v2"
 )
 
@@ -30,9 +31,14 @@ type CourseYaml struct {
 	Number                    string              `yaml:"number,omitempty"`
 	Slug                      string              `yaml:"slug,omitempty"`
 	StartDate                 string              `yaml:"start_date,omitempty"`
+	Timezone                  string              `yaml:"cli_timezone,omitempty"`
 	SyncStatusUpdateTimestamp string              `yaml:"sync_status_update_timestamp,omitempty"`
 	CLIDirectoryWordSeparator string              `yaml:"cli_directory_word_separator,omitempty"`
+	CLIDirectoryMaxLength     int                 `yaml:"cli_directory_max_length,omitempty"`
+	CLIDirectoryKeepUnicode   bool                `yaml:"cli_directory_keep_unicode,omitempty"`
+	CLIDirectoryReplacements  map[string]string   `yaml:"cli_directory_replacements,omitempty"`
 	CLICommitSHA              string              `yaml:"cli_commit_sha,omitempty"`
+	StripNotebookOutputs      bool                `yaml:"cli_strip_notebook_outputs,omitempty"`
 }
 
 var CourseTypes = [...]string{"semester", "ongoing", "mooc"}
@@ -78,9 +84,21 @@ type ContentBlockYaml struct {
 	YoutubeVideoID   string   `yaml:"youtube_video_id,omitempty"`
 	NUploads         int      `yaml:"n_uploads,omitempty"`
 	UploadExtensions []string `yaml:"upload_extensions,omitempty"`
+	DueAt            string   `yaml:"cli_due_at,omitempty"`
 	Quiz             Quiz     `yaml:"quiz,omitempty"`
 }
 
+// VideoURL returns the address a video block can be watched at
+func (cb ContentBlockYaml) VideoURL() string {
+	switch {
+	case cb.YoutubeVideoID != "":
+		return "https://www.youtube.com/watch?v=" + cb.YoutubeVideoID
+	case cb.VimeoVideoID != "":
+		return "https://vimeo.com/" + cb.VimeoVideoID
+	}
+	return cb.URL
+}
+
 var (
 	ContentBlockTypes = [...]string{"video", "notebook", "markdown", "link", "quiz"}
 	VideoSources      = [...]string{"youtube", "vimeo", "url"}
@@ -168,6 +186,39 @@ func ParseLectureYaml(lectureYmlPath string) (*LectureYaml, error) {
 
 type SpecForZip struct {
 	Name, Path string
+	// Content replaces the content of the file at Path when it is generated
+	// by the CLI, e.g. a `_lecture.yml` with quizzes compiled from markdown
+	Content []byte
+	// DependsOn lists the other files Content was generated from
+	DependsOn []string
+}
+
+// ZipOptions controls how CreateZip packages the course
+type ZipOptions struct {
+	// StripOutputs sends notebooks without outputs or execution counts. It is
+	// also enabled by `cli_strip_notebook_outputs` in `_course.yml`.
+	StripOutputs bool
+}
+
+// readFileForZip returns the content of file as it should appear in the zip
+func readFileForZip(file SpecForZip, stripOutputs bool) ([]byte, error) {
+	if file.Content != nil {
+		return file.Content, nil
+	}
+	fileContent, err := os.ReadFile(file.Path)
+	if err != nil {
+		return nil, err
+	}
+	if !stripOutputs || filepath.Ext(file.Path) != ".ipynb" {
+		return fileContent, nil
+	}
+
+	nb, err := notebook.Parse(fileContent)
+	if err != nil {
+		return nil, fmt.Errorf("unable to strip outputs from %s: %w", file.Path, err)
+	}
+	nb.StripOutputs()
+	return notebook.Marshal(nb)
 }
 
 func (c *CourseYaml) WriteYaml(dirname string) error {
@@ -191,7 +242,37 @@ func (c *CourseYaml) WriteYaml(dirname string) error {
 	return nil
 }
 
-func (c *CourseYaml) CreateZip(path string) ([]byte, []SpecForZip, error) {
+// specForZip returns how the `_lecture.yml` at path is added to the push zip.
+// Quizzes written in markdown are compiled into the lecture so the server
+// receives them in the usual inline form.
+func (lecture *LectureYaml) specForZip(name, path, lectureDir string) (SpecForZip, error) {
+	spec := SpecForZip{Name: name, Path: path}
+	compiled := false
+	for i, cb := range lecture.ContentBlocks {
+		if cb.Type != "quiz" || !IsQuizMarkdown(cb.Filename) {
+			continue
+		}
+		quiz, err := cb.LoadQuiz(lectureDir)
+		if err != nil {
+			return spec, err
+		}
+		lecture.ContentBlocks[i].Quiz = quiz
+		spec.DependsOn = append(spec.DependsOn, filepath.Join(lectureDir, cb.Filename))
+		compiled = true
+	}
+	if !compiled {
+		return spec, nil
+	}
+
+	content, err := yaml.Marshal(lecture)
+	if err != nil {
+		return spec, err
+	}
+	spec.Content = content
+	return spec, nil
+}
+
+func (c *CourseYaml) CreateZip(path string, opts ZipOptions) ([]byte, []SpecForZip, error) {
 	// first create a zip file in memory and load up the file at `path/_course.yml` and `path/syllabus.md`
 	// then add all the files in the lectures directories
 	// then return the zip file as a byte slice
@@ -203,7 +284,7 @@ func (c *CourseYaml) CreateZip(path string) ([]byte, []SpecForZip, error) {
 
 	// Add files to the archive.
 	files := []SpecForZip{
-		{"syllabus.md", filepath.Join(path, "syllabus.md")},
+		{Name: "syllabus.md", Path: filepath.Join(path, "syllabus.md")},
 	}
 
 	// loop over c.lectures
@@ -215,13 +296,15 @@ func (c *CourseYaml) CreateZip(path string) ([]byte, []SpecForZip, error) {
 			return nil, nil, err
 		}
 
-		files = append(
-			files,
-			SpecForZip{
-				Name: filepath.Join(l.Directory, "_lecture.yml"),
-				Path: lectureYamlPath,
-			},
+		lectureSpec, err := lecture.specForZip(
+			filepath.Join(l.Directory, "_lecture.yml"),
+			lectureYamlPath,
+			filepath.Join(path, l.Directory),
 		)
+		if err != nil {
+			return nil, nil, err
+		}
+		files = append(files, lectureSpec)
 
 		// loop over lecture.ContentBlocks
 		for _, cb := range lecture.ContentBlocks {
@@ -243,7 +326,7 @@ func (c *CourseYaml) CreateZip(path string) ([]byte, []SpecForZip, error) {
 			return nil, nil, err
 		}
 
-		fileContent, err := os.ReadFile(file.Path)
+		fileContent, err := readFileForZip(file, opts.StripOutputs || c.StripNotebookOutputs)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -254,8 +337,9 @@ func (c *CourseYaml) CreateZip(path string) ([]byte, []SpecForZip, error) {
 		}
 	}
 
-	// finally marshal the course yaml and write it to the zip file
-	courseYamlBytes, err := yaml.Marshal(c)
+	// finally marshal the course yaml, with local and relative times
+	// resolved for the server, and write it to the zip file
+	courseYamlBytes, err := yaml.Marshal(c.NormalizeTimes(time.Now()))
 	if err != nil {
 		return nil, nil, err
 	}
@@ -289,9 +373,9 @@ func (c *CourseYaml) CheckLectureDirectories() error {
 		if err != nil {
 			return err
 		}
-		// Check slugified version
-		slug := Slugify(lecture.Title, c.Sep())
-		if cl.Directory != slug {
+		// Check slugified version, accepting names made before the slug rules
+		slug := c.SlugRules().Slugify(lecture.Title)
+		if cl.Directory != slug && cl.Directory != legacySlugify(lecture.Title, c.Sep()) {
 			return fmt.Errorf("Directory name %s does not match lecture title %s", cl.Directory, slug)
 		}
 	}
@@ -0,0 +1,90 @@
+package model
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sglyon/jupyteach/internal/notebook"
+)
+
+const notebookWithOutputs = `{
+ "cells": [
+  {
+   "cell_type": "code",
+   "execution_count": 3,
+   "metadata": {},
+   "outputs": [
+    {"name": "stdout", "output_type": "stream", "text": ["hi\n"]}
+   ],
+   "source": ["print('hi')"]
+  }
+ ],
+ "metadata": {},
+ "nbformat": 4,
+ "nbformat_minor": 5
+}
+`
+
+func TestCreateZipStripOutputs(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		course string
+		opts   ZipOptions
+	}{
+		{"option", "slug: demo\nlectures:\n- directory: intro\n", ZipOptions{StripOutputs: true}},
+		{"course setting", "slug: demo\ncli_strip_notebook_outputs: true\nlectures:\n- directory: intro\n", ZipOptions{}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, map[string]string{
+				"_course.yml":        tc.course,
+				"syllabus.md":        "# Syllabus\n",
+				"intro/_lecture.yml": "title: Intro\ncontent_blocks:\n- type: notebook\n  title: Hello\n  filename: hello.ipynb\n",
+				"intro/hello.ipynb":  notebookWithOutputs,
+			})
+			course, err := ParseCourseYaml(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			data, _, err := course.CreateZip(dir, tc.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			f, err := r.Open("intro/hello.ipynb")
+			if err != nil {
+				t.Fatal(err)
+			}
+			zipped, err := io.ReadAll(f)
+			f.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+			nb, err := notebook.Parse(zipped)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, c := range nb.Cells {
+				if len(c.Outputs) != 0 || c.ExecutionCount != nil {
+					t.Errorf("zipped cell kept outputs %v or execution count %v", c.Outputs, c.ExecutionCount)
+				}
+			}
+
+			onDisk, err := os.ReadFile(filepath.Join(dir, "intro", "hello.ipynb"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(onDisk) != notebookWithOutputs {
+				t.Errorf("notebook on disk changed:\n%s", onDisk)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	// QuestionTypes are the question types the CLI knows how to check. The
+	// server may accept others, so an unknown type is only a warning.
+	QuestionTypes = [...]string{"single_selection", "multiple_selection", "code", "freeform", "fill_in_blank"}
+	Difficulties  = [...]string{"easy", "medium", "hard"}
+)
+
+// fillInBlankPattern matches a blank in the starting code of a fill in the
+// blank question: three or more underscores
+var fillInBlankPattern = regexp.MustCompile(`_{3,}`)
+
+// QuestionIssue is a problem with a single quiz question. Field is the yaml
+// key the problem relates to so callers can point at the right line.
+type QuestionIssue struct {
+	Severity Severity
+	Field    string
+	Message  string
+}
+
+func (i QuestionIssue) String() string {
+	return fmt.Sprintf("%s: %s", i.Field, i.Message)
+}
+
+// questionFields lists which of the type specific fields apply to each question type
+var questionFields = map[string][]string{
+	"single_selection":   {"options"},
+	"multiple_selection": {"options"},
+	"code":               {"starting_code", "setup_code", "test_code"},
+	"freeform":           {"starting_code"},
+	"fill_in_blank":      {"starting_code"},
+}
+
+func (q Question) fieldSet(field string) bool {
+	switch field {
+	case "options":
+		return len(q.Options) > 0
+	case "starting_code":
+		return q.StartingCode != ""
+	case "setup_code":
+		return q.SetupCode != ""
+	case "test_code":
+		return q.TestCode != ""
+	}
+	return false
+}
+
+// ParseMultipleSelectionSolution parses the solution of a multiple selection
+// question, which is a yaml (or json) list of the correct options
+func ParseMultipleSelectionSolution(solution string) ([]string, error) {
+	var out []string
+	if err := yaml.Unmarshal([]byte(solution), &out); err != nil {
+		return nil, fmt.Errorf("solution must be a list of options such as [\"a\", \"b\"]: %w", err)
+	}
+	return out, nil
+}
+
+// FormatMultipleSelectionSolution writes the correct options of a multiple
+// selection question in the format read by ParseMultipleSelectionSolution
+func FormatMultipleSelectionSolution(options []string) string {
+	quoted := make([]string, len(options))
+	for i, o := range options {
+		quoted[i] = strconv.Quote(o)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+// Validate checks that the question is consistent with its QuestionType
+func (q Question) Validate() []QuestionIssue {
+	var issues []QuestionIssue
+	errorf := func(field, format string, args ...interface{}) {
+		issues = append(issues, QuestionIssue{SeverityError, field, fmt.Sprintf(format, args...)})
+	}
+	warnf := func(field, format string, args ...interface{}) {
+		issues = append(issues, QuestionIssue{SeverityWarning, field, fmt.Sprintf(format, args...)})
+	}
+
+	if strings.TrimSpace(q.QuestionText) == "" {
+		errorf("question_text", "question has no text")
+	}
+	if q.Points <= 0 {
+		errorf("points", "points must be positive, got %d", q.Points)
+	}
+	if q.Difficulty != "" && !contains(Difficulties[:], q.Difficulty) {
+		errorf("difficulty", "invalid difficulty %q, must be one of %s", q.Difficulty, strings.Join(Difficulties[:], ", "))
+	}
+
+	if q.QuestionType == "" {
+		errorf("question_type", "question has no question_type")
+		return issues
+	}
+	allowed, ok := questionFields[q.QuestionType]
+	if !ok {
+		warnf("question_type", "unknown question_type %q, expected one of %s; it is not checked", q.QuestionType, strings.Join(QuestionTypes[:], ", "))
+		return issues
+	}
+	for _, field := range []string{"options", "starting_code", "setup_code", "test_code"} {
+		if q.fieldSet(field) && !contains(allowed, field) {
+			warnf(field, "%s is ignored for %s questions", field, q.QuestionType)
+		}
+	}
+
+	switch q.QuestionType {
+	case "single_selection":
+		if len(q.Options) < 2 {
+			errorf("options", "single_selection questions need at least two options")
+		}
+		if !contains(q.Options, q.Solution) {
+			errorf("solution", "solution %q is not one of the options", q.Solution)
+		}
+	case "multiple_selection":
+		if len(q.Options) < 2 {
+			errorf("options", "multiple_selection questions need at least two options")
+		}
+		solutions, err := ParseMultipleSelectionSolution(q.Solution)
+		if err != nil {
+			errorf("solution", "%s", err)
+			break
+		}
+		if len(solutions) == 0 {
+			errorf("solution", "multiple_selection solution must list at least one option")
+		}
+		for _, s := range solutions {
+			if !contains(q.Options, s) {
+				errorf("solution", "solution %q is not one of the options", s)
+			}
+		}
+	case "code":
+		if strings.TrimSpace(q.Solution) == "" {
+			errorf("solution", "code questions need a solution")
+		}
+		if strings.TrimSpace(q.TestCode) == "" {
+			errorf("test_code", "code questions need test_code to grade submissions")
+		}
+	case "fill_in_blank":
+		if !fillInBlankPattern.MatchString(q.StartingCode) {
+			errorf("starting_code", "fill_in_blank starting_code must contain at least one blank (___)")
+		}
+		if strings.TrimSpace(q.Solution) == "" {
+			errorf("solution", "fill_in_blank questions need a solution")
+		}
+	}
+
+	return issues
+}
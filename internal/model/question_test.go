@@ -0,0 +1,79 @@
+package model
+
+import (
+	"testing"
+)
+
+func TestQuestionValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		question Question
+		fields   []string
+	}{
+		{
+			"valid single selection",
+			Question{QuestionType: "single_selection", QuestionText: "2+2?", Points: 1, Options: []string{"3", "4"}, Solution: "4"},
+			nil,
+		},
+		{
+			"single selection solution not in options",
+			Question{QuestionType: "single_selection", QuestionText: "2+2?", Points: 1, Options: []string{"3", "4"}, Solution: "5"},
+			[]string{"solution"},
+		},
+		{
+			"valid multiple selection",
+			Question{QuestionType: "multiple_selection", QuestionText: "evens?", Points: 2, Options: []string{"1", "2", "4"}, Solution: `["2", "4"]`},
+			nil,
+		},
+		{
+			"multiple selection solution does not parse",
+			Question{QuestionType: "multiple_selection", QuestionText: "evens?", Points: 2, Options: []string{"1", "2"}, Solution: "{2"},
+			[]string{"solution"},
+		},
+		{
+			"code without tests",
+			Question{QuestionType: "code", QuestionText: "add", Points: 1, Solution: "def f(): pass", Options: []string{"x"}},
+			[]string{"options", "test_code"},
+		},
+		{
+			"fill in blank without blank",
+			Question{QuestionType: "fill_in_blank", QuestionText: "fill", Points: 1, StartingCode: "x = 1", Solution: "1"},
+			[]string{"starting_code"},
+		},
+		{
+			"bad difficulty and points",
+			Question{QuestionType: "freeform", QuestionText: "why?", Difficulty: "extreme"},
+			[]string{"points", "difficulty"},
+		},
+		{
+			"unknown type",
+			Question{QuestionType: "essay", QuestionText: "why?", Points: 1},
+			[]string{"question_type"},
+		},
+		{
+			"missing type",
+			Question{QuestionText: "why?", Points: 1},
+			[]string{"question_type"},
+		},
+	}
+
+	for _, test := range tests {
+		issues := test.question.Validate()
+		if len(issues) != len(test.fields) {
+			t.Errorf("%s: expected issues for %v, got %v", test.name, test.fields, issues)
+			continue
+		}
+		for i, issue := range issues {
+			if issue.Field != test.fields[i] {
+				t.Errorf("%s: expected issue %d on %s, got %v", test.name, i, test.fields[i], issue)
+			}
+		}
+	}
+}
+
+func TestQuestionValidateUnknownTypeWarns(t *testing.T) {
+	issues := Question{QuestionType: "essay", QuestionText: "why?", Points: 1}.Validate()
+	if len(issues) != 1 || issues[0].Severity != SeverityWarning {
+		t.Errorf("expected a single warning for an unknown question_type, got %v", issues)
+	}
+}
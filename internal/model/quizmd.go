@@ -0,0 +1,485 @@
+package model
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// A quiz can be written in markdown instead of inline in `_lecture.yml`. The
+// quiz content block then points at the file with `filename: quiz.md` and the
+// CLI compiles it into a Quiz when building the push zip. The format is:
+//
+//	---
+//	max_attempts: 3
+//	topics: [loops]
+//	---
+//
+//	```python start
+//	import math
+//	```
+//
+//	## Question 1
+//
+//	type: single_selection
+//	points: 2
+//	difficulty: easy
+//	topics: loops, functions
+//
+//	What is 2 + 2?
+//
+//	- [ ] 3
+//	- [x] 4
+//
+// Every `##` heading starts a question. The `key: value` lines right after
+// the heading set the question fields, checkbox lists give the options (the
+// checked ones are the solution), and fenced code blocks whose info string
+// ends in `setup`, `starting`, `solution` or `test` hold the code fields.
+// Everything else is the question text.
+
+var (
+	quizMarkdownAttribute = regexp.MustCompile(`^([a-z_]+):\s*(.*)$`)
+	quizMarkdownCheckbox  = regexp.MustCompile(`^\s*[-*]\s+\[([ xX])\]\s+(.*)$`)
+	quizMarkdownFence     = regexp.MustCompile("^\\s*(`{3,}|~{3,})\\s*(.*)$")
+)
+
+// quizMarkdownRoles maps the role at the end of a fence info string to the
+// question field it fills. `start` sets the quiz start code.
+var quizMarkdownRoles = map[string]string{
+	"start":    "start_code",
+	"setup":    "setup_code",
+	"starting": "starting_code",
+	"solution": "solution",
+	"test":     "test_code",
+}
+
+// quizMarkdownPositions records where each question (and its fields) starts
+// in the markdown file so diagnostics can point at the right line
+type quizMarkdownPositions []map[string]int
+
+func (p quizMarkdownPositions) Line(question int, field string) int {
+	if question < 0 || question >= len(p) {
+		return 0
+	}
+	if line, ok := p[question][field]; ok {
+		return line
+	}
+	return p[question][""]
+}
+
+func IsQuizMarkdown(filename string) bool {
+	return strings.EqualFold(filepath.Ext(filename), ".md")
+}
+
+// ParseQuizMarkdown compiles a markdown quiz into a Quiz
+func ParseQuizMarkdown(data []byte) (*Quiz, error) {
+	quiz, _, err := parseQuizMarkdown(data)
+	return quiz, err
+}
+
+type quizMarkdownQuestion struct {
+	question Question
+	text     []string
+	options  []string
+	checked  []string
+	inAttrs  bool
+	// solution is set when given as an attribute or fenced block
+	hasSolution bool
+}
+
+func (q *quizMarkdownQuestion) finish() Question {
+	out := q.question
+	out.QuestionText = strings.TrimSpace(strings.Join(q.text, "\n"))
+	if len(q.options) > 0 {
+		out.Options = q.options
+	}
+	if out.QuestionType == "" {
+		switch {
+		case len(q.options) > 0 && len(q.checked) == 1:
+			out.QuestionType = "single_selection"
+		case len(q.options) > 0:
+			out.QuestionType = "multiple_selection"
+		case out.TestCode != "":
+			out.QuestionType = "code"
+		case fillInBlankPattern.MatchString(out.StartingCode):
+			out.QuestionType = "fill_in_blank"
+		default:
+			out.QuestionType = "freeform"
+		}
+	}
+	if !q.hasSolution && len(q.options) > 0 {
+		if out.QuestionType == "single_selection" && len(q.checked) == 1 {
+			out.Solution = q.checked[0]
+		} else if out.QuestionType == "multiple_selection" {
+			out.Solution = FormatMultipleSelectionSolution(q.checked)
+		}
+	}
+	return out
+}
+
+func splitTopics(s string) []string {
+	var topics []string
+	for _, t := range strings.Split(s, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			topics = append(topics, t)
+		}
+	}
+	return topics
+}
+
+// quizMarkdownAttributes are the keys accepted in the lines after a question
+// heading, mapped to the question field they set
+var quizMarkdownAttributes = map[string]string{
+	"type":          "question_type",
+	"question_type": "question_type",
+	"id":            "id",
+	"points":        "points",
+	"difficulty":    "difficulty",
+	"topics":        "topics",
+	"solution":      "solution",
+}
+
+func (q *quizMarkdownQuestion) setAttribute(key, value string) error {
+	switch key {
+	case "type", "question_type":
+		q.question.QuestionType = value
+	case "id":
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid id %q", value)
+		}
+		q.question.ID = id
+	case "points":
+		points, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid points %q", value)
+		}
+		q.question.Points = points
+	case "difficulty":
+		q.question.Difficulty = value
+	case "topics":
+		q.question.Topics = splitTopics(value)
+	case "solution":
+		q.question.Solution = value
+		q.hasSolution = true
+	}
+	return nil
+}
+
+func (q *quizMarkdownQuestion) setCode(field, code string) {
+	switch field {
+	case "setup_code":
+		q.question.SetupCode = code
+	case "starting_code":
+		q.question.StartingCode = code
+	case "solution":
+		q.question.Solution = code
+		q.hasSolution = true
+	case "test_code":
+		q.question.TestCode = code
+	}
+}
+
+func parseQuizMarkdown(data []byte) (*Quiz, quizMarkdownPositions, error) {
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	quiz := &Quiz{}
+	var positions quizMarkdownPositions
+	var current *quizMarkdownQuestion
+
+	i := 0
+	if len(lines) > 0 && strings.TrimSpace(lines[0]) == "---" {
+		end := -1
+		for j := 1; j < len(lines); j++ {
+			if strings.TrimSpace(lines[j]) == "---" {
+				end = j
+				break
+			}
+		}
+		if end < 0 {
+			return nil, nil, fmt.Errorf("line 1: front matter is not closed with ---")
+		}
+		if err := yaml.Unmarshal([]byte(strings.Join(lines[1:end], "\n")), quiz); err != nil {
+			return nil, nil, fmt.Errorf("line 1: invalid front matter: %w", err)
+		}
+		quiz.Questions = nil
+		i = end + 1
+	}
+
+	flush := func() {
+		if current != nil {
+			quiz.Questions = append(quiz.Questions, current.finish())
+		}
+	}
+
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		lineNo := i + 1
+
+		if m := quizMarkdownFence.FindStringSubmatch(line); m != nil {
+			fence := m[1]
+			info := strings.Fields(m[2])
+			end := -1
+			for j := i + 1; j < len(lines); j++ {
+				if strings.HasPrefix(strings.TrimSpace(lines[j]), fence) && strings.Trim(strings.TrimSpace(lines[j]), fence[:1]) == "" {
+					end = j
+					break
+				}
+			}
+			if end < 0 {
+				return nil, nil, fmt.Errorf("line %d: code block is not closed", lineNo)
+			}
+
+			role := ""
+			if len(info) > 0 {
+				role = quizMarkdownRoles[info[len(info)-1]]
+			}
+			code := strings.Join(lines[i+1:end], "\n")
+			if code != "" {
+				code += "\n"
+			}
+			switch {
+			case role == "start_code":
+				quiz.StartCode = code
+			case role != "" && current == nil:
+				return nil, nil, fmt.Errorf("line %d: %s code block must be inside a question", lineNo, info[len(info)-1])
+			case role != "":
+				current.setCode(role, code)
+				positions[len(positions)-1][role] = lineNo
+			case current != nil:
+				current.text = append(current.text, lines[i:end+1]...)
+			}
+			if current != nil {
+				current.inAttrs = false
+			}
+			i = end
+			continue
+		}
+
+		if strings.HasPrefix(line, "## ") {
+			flush()
+			current = &quizMarkdownQuestion{inAttrs: true}
+			positions = append(positions, map[string]int{"": lineNo})
+			continue
+		}
+
+		if current == nil {
+			// anything before the first question (e.g. a title) is ignored
+			continue
+		}
+
+		if current.inAttrs {
+			if strings.TrimSpace(line) == "" {
+				if len(positions[len(positions)-1]) > 1 {
+					current.inAttrs = false
+				}
+				continue
+			}
+			if m := quizMarkdownAttribute.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+				if field, ok := quizMarkdownAttributes[m[1]]; ok {
+					if err := current.setAttribute(m[1], strings.TrimSpace(m[2])); err != nil {
+						return nil, nil, fmt.Errorf("line %d: %w", lineNo, err)
+					}
+					positions[len(positions)-1][field] = lineNo
+					continue
+				}
+			}
+			current.inAttrs = false
+		}
+
+		if m := quizMarkdownCheckbox.FindStringSubmatch(line); m != nil {
+			option := strings.TrimSpace(m[2])
+			current.options = append(current.options, option)
+			if m[1] != " " {
+				current.checked = append(current.checked, option)
+			}
+			if _, ok := positions[len(positions)-1]["options"]; !ok {
+				positions[len(positions)-1]["options"] = lineNo
+			}
+			continue
+		}
+
+		current.text = append(current.text, line)
+	}
+	flush()
+
+	if quiz.Questions == nil {
+		quiz.Questions = []Question{}
+	}
+	return quiz, positions, nil
+}
+
+// fenceFor returns a code fence long enough to wrap code
+func fenceFor(code string) string {
+	longest := 0
+	for _, line := range strings.Split(code, "\n") {
+		trimmed := strings.TrimSpace(line)
+		n := len(trimmed) - len(strings.TrimLeft(trimmed, "`"))
+		if n > longest {
+			longest = n
+		}
+	}
+	if longest < 3 {
+		return "```"
+	}
+	return strings.Repeat("`", longest+1)
+}
+
+func writeFence(buf *bytes.Buffer, info, code string) {
+	fence := fenceFor(code)
+	fmt.Fprintf(buf, "%s%s\n%s", fence, info, code)
+	if !strings.HasSuffix(code, "\n") {
+		buf.WriteString("\n")
+	}
+	fmt.Fprintf(buf, "%s\n\n", fence)
+}
+
+// FormatQuizMarkdown writes quiz in the markdown quiz format. Code blocks are
+// labeled with language, e.g. python.
+func FormatQuizMarkdown(quiz Quiz, language string) ([]byte, error) {
+	var buf bytes.Buffer
+
+	if quiz.QuizID != 0 || quiz.MaxAttempts != 0 || len(quiz.Topics) > 0 {
+		frontMatter, err := yaml.Marshal(struct {
+			QuizID      int      `yaml:"quiz_id,omitempty"`
+			MaxAttempts int      `yaml:"max_attempts,omitempty"`
+			Topics      []string `yaml:"topics,omitempty"`
+		}{quiz.QuizID, quiz.MaxAttempts, quiz.Topics})
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&buf, "---\n%s---\n\n", frontMatter)
+	}
+
+	if quiz.StartCode != "" {
+		writeFence(&buf, language+" start", quiz.StartCode)
+	}
+
+	for i, q := range quiz.Questions {
+		fmt.Fprintf(&buf, "## Question %d\n\n", i+1)
+		fmt.Fprintf(&buf, "type: %s\n", q.QuestionType)
+		if q.ID != 0 {
+			fmt.Fprintf(&buf, "id: %d\n", q.ID)
+		}
+		if q.Points != 0 {
+			fmt.Fprintf(&buf, "points: %d\n", q.Points)
+		}
+		if q.Difficulty != "" {
+			fmt.Fprintf(&buf, "difficulty: %s\n", q.Difficulty)
+		}
+		if len(q.Topics) > 0 {
+			fmt.Fprintf(&buf, "topics: %s\n", strings.Join(q.Topics, ", "))
+		}
+
+		selection := q.QuestionType == "single_selection" || q.QuestionType == "multiple_selection"
+		if selection {
+			// the solution is recovered from the checked options, unless it
+			// doesn't match them in which case it is kept verbatim
+			if _, err := selectedOptions(q); err != nil && q.Solution != "" && !strings.Contains(q.Solution, "\n") {
+				fmt.Fprintf(&buf, "solution: %s\n", q.Solution)
+			}
+		}
+		buf.WriteString("\n")
+
+		if q.QuestionText != "" {
+			fmt.Fprintf(&buf, "%s\n\n", strings.TrimSpace(q.QuestionText))
+		}
+
+		if selection {
+			checked, _ := selectedOptions(q)
+			for _, option := range q.Options {
+				mark := " "
+				if contains(checked, option) {
+					mark = "x"
+				}
+				fmt.Fprintf(&buf, "- [%s] %s\n", mark, option)
+			}
+			buf.WriteString("\n")
+		}
+
+		for _, block := range []struct{ role, code string }{
+			{"setup", q.SetupCode},
+			{"starting", q.StartingCode},
+			{"solution", q.Solution},
+			{"test", q.TestCode},
+		} {
+			if block.code == "" || (selection && block.role == "solution") {
+				continue
+			}
+			writeFence(&buf, language+" "+block.role, block.code)
+		}
+	}
+
+	return append(bytes.TrimRight(buf.Bytes(), "\n"), '\n'), nil
+}
+
+// selectedOptions returns the options that make up the solution of a
+// selection question
+func selectedOptions(q Question) ([]string, error) {
+	switch q.QuestionType {
+	case "single_selection":
+		if !contains(q.Options, q.Solution) {
+			return nil, fmt.Errorf("solution is not one of the options")
+		}
+		return []string{q.Solution}, nil
+	case "multiple_selection":
+		solutions, err := ParseMultipleSelectionSolution(q.Solution)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range solutions {
+			if !contains(q.Options, s) {
+				return nil, fmt.Errorf("solution %q is not one of the options", s)
+			}
+		}
+		return solutions, nil
+	}
+	return nil, nil
+}
+
+// LoadQuiz returns the quiz of a quiz content block. When the block points at
+// a markdown file (relative to lectureDir) the quiz is compiled from it, with
+// ids carried over from the inline quiz the server last sent back.
+func (cb ContentBlockYaml) LoadQuiz(lectureDir string) (Quiz, error) {
+	if cb.Filename == "" || !IsQuizMarkdown(cb.Filename) {
+		return cb.Quiz, nil
+	}
+	quiz, _, err := cb.loadQuizMarkdown(lectureDir)
+	if err != nil {
+		return Quiz{}, err
+	}
+	return *quiz, nil
+}
+
+func (cb ContentBlockYaml) loadQuizMarkdown(lectureDir string) (*Quiz, quizMarkdownPositions, error) {
+	data, err := os.ReadFile(filepath.Join(lectureDir, cb.Filename))
+	if err != nil {
+		return nil, nil, err
+	}
+	quiz, positions, err := parseQuizMarkdown(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", cb.Filename, err)
+	}
+
+	if quiz.QuizID == 0 {
+		quiz.QuizID = cb.Quiz.QuizID
+	}
+	for i := range quiz.Questions {
+		q := &quiz.Questions[i]
+		if q.ID != 0 {
+			continue
+		}
+		for _, inline := range cb.Quiz.Questions {
+			if inline.ID != 0 && inline.QuestionText == q.QuestionText {
+				q.ID = inline.ID
+				break
+			}
+		}
+	}
+	return quiz, positions, nil
+}
@@ -0,0 +1,78 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestQuizMarkdownRoundTrip(t *testing.T) {
+	quiz := Quiz{
+		QuizID:      7,
+		MaxAttempts: 3,
+		Topics:      []string{"basics"},
+		StartCode:   "import math\n",
+		Questions: []Question{
+			{
+				ID:           11,
+				QuestionType: "single_selection",
+				QuestionText: "What is 2 + 2?",
+				Points:       1,
+				Options:      []string{"3", "4"},
+				Solution:     "4",
+			},
+			{
+				QuestionType: "multiple_selection",
+				QuestionText: "Pick the **even** numbers\n\nAll of them.",
+				Topics:       []string{"parity", "numbers"},
+				Difficulty:   "easy",
+				Points:       2,
+				Options:      []string{"1", "2", "4"},
+				Solution:     `["2", "4"]`,
+			},
+			{
+				QuestionType: "code",
+				QuestionText: "Write `add`:\n\n```python\nadd(1, 2) == 3\n```",
+				Points:       5,
+				SetupCode:    "import operator\n",
+				StartingCode: "def add(a, b):\n    pass\n",
+				Solution:     "def add(a, b):\n    return a + b\n",
+				TestCode:     "assert add(1, 2) == 3\n",
+			},
+			{
+				QuestionType: "fill_in_blank",
+				QuestionText: "Complete the loop",
+				Points:       1,
+				StartingCode: "for i in ___(3):\n    print(i)\n",
+				Solution:     "range\n",
+			},
+		},
+	}
+
+	md, err := FormatQuizMarkdown(quiz, "python")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ParseQuizMarkdown(md)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(*parsed, quiz) {
+		t.Errorf("round trip changed quiz:\n%s\ngot  %+v\nwant %+v", md, *parsed, quiz)
+	}
+}
+
+func TestParseQuizMarkdownPositions(t *testing.T) {
+	md := "# Quiz\n\n## First\n\ntype: single_selection\npoints: 0\n\nPick one\n\n- [ ] a\n- [ ] b\n"
+	quiz, positions, err := parseQuizMarkdown([]byte(md))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(quiz.Questions) != 1 {
+		t.Fatalf("expected 1 question, got %d", len(quiz.Questions))
+	}
+	for field, line := range map[string]int{"": 3, "points": 6, "options": 10, "solution": 3} {
+		if got := positions.Line(0, field); got != line {
+			t.Errorf("expected %q on line %d, got %d", field, line, got)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package model
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// LectureIndex returns the position in the course of the lecture in
+// directory dir
+func (c *CourseYaml) LectureIndex(dir string) (int, error) {
+	dir = filepath.Clean(dir)
+	for i, cl := range c.Lectures {
+		if filepath.Clean(cl.Directory) == dir {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("no lecture in directory %s in _course.yml", dir)
+}
+
+// RenameLecture gives the lecture in directory dir of the course in dirname a
+// new title and moves it, with move, to the directory named after the title.
+// An empty title keeps the current one, which only brings the directory name
+// up to date. The lecture keeps its ids, so the server sees the same lecture
+// under a new name. The new directory is written to `_course.yml` and
+// returned.
+func (c *CourseYaml) RenameLecture(dirname, dir, title string, move func(from, to string) error) (string, error) {
+	i, err := c.LectureIndex(dir)
+	if err != nil {
+		return "", err
+	}
+	dir = c.Lectures[i].Directory
+	lectureFile := filepath.Join(dirname, dir, "_lecture.yml")
+	lecture, err := ParseLectureYaml(lectureFile)
+	if err != nil {
+		return "", err
+	}
+	if title == "" {
+		title = lecture.Title
+	}
+
+	newDir := c.SlugRules().Slugify(title)
+	if newDir == "" {
+		return "", fmt.Errorf("the title %q gives an empty directory name, add cli_directory_replacements to _course.yml or pick another title", title)
+	}
+	if title == lecture.Title && newDir == dir {
+		return dir, nil
+	}
+	if newDir != dir {
+		if _, err := os.Stat(filepath.Join(dirname, newDir)); err == nil {
+			return "", fmt.Errorf("%s already exists", filepath.Join(dirname, newDir))
+		}
+	}
+
+	// move first so a failed move leaves the lecture as it was
+	if newDir != dir {
+		if err := move(dir, newDir); err != nil {
+			return "", err
+		}
+		lectureFile = filepath.Join(dirname, newDir, "_lecture.yml")
+	}
+	if title != lecture.Title {
+		lecture.Title = title
+		if err := lecture.WriteYaml(lectureFile); err != nil {
+			return "", err
+		}
+	}
+	if newDir == dir {
+		return dir, nil
+	}
+	c.Lectures[i].Directory = newDir
+	if err := c.WriteYaml(dirname); err != nil {
+		return "", err
+	}
+	return newDir, nil
+}
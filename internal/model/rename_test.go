@@ -0,0 +1,86 @@
+package model
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRenameLecture(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"_course.yml": `slug: econ-101
+lectures:
+- directory: intro
+  course_lecture_id: 11
+  lecture_id: 12
+- directory: week_2
+  lecture_id: 13
+`,
+		"intro/_lecture.yml":  "title: Intro\nlecture_id: 12\n",
+		"week_2/_lecture.yml": "title: Week 2\nlecture_id: 13\n",
+		"taken/_lecture.yml":  "title: Taken\n",
+	})
+	course, err := ParseCourseYaml(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rename := func(from, to string) error {
+		return os.Rename(filepath.Join(dir, from), filepath.Join(dir, to))
+	}
+
+	newDir, err := course.RenameLecture(dir, "intro", "Introduction to Python", rename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if newDir != "introduction-to-python" {
+		t.Errorf("new directory %q", newDir)
+	}
+	course, err = ParseCourseYaml(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cl := course.Lectures[0]
+	if cl.Directory != newDir || cl.CourseLectureID != 11 || cl.LectureID != 12 {
+		t.Errorf("course lecture is %+v", cl)
+	}
+	lecture, err := ParseLectureYaml(filepath.Join(dir, newDir, "_lecture.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lecture.Title != "Introduction to Python" || lecture.LectureID != 12 {
+		t.Errorf("lecture is %+v", lecture)
+	}
+
+	// without a title only the directory follows the current title
+	if newDir, err := course.RenameLecture(dir, "week_2", "", rename); err != nil || newDir != "week-2" {
+		t.Errorf("got %q, %v", newDir, err)
+	}
+
+	if _, err := course.RenameLecture(dir, "week-2", "Taken", rename); err == nil {
+		t.Error("expected an error renaming onto an existing directory")
+	}
+	if _, err := course.RenameLecture(dir, "missing", "Title", rename); err == nil {
+		t.Error("expected an error for a directory not in the course")
+	}
+	if _, err := course.RenameLecture(dir, "week-2", "!!!", rename); err == nil {
+		t.Error("expected an error for a title without a directory name")
+	}
+
+	// a failed move leaves the title and _course.yml as they were
+	failed := func(from, to string) error { return errors.New("git mv failed") }
+	if _, err := course.RenameLecture(dir, "week-2", "Second week", failed); err == nil {
+		t.Error("expected the move error")
+	}
+	lecture, err = ParseLectureYaml(filepath.Join(dir, "week-2", "_lecture.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lecture.Title != "Week 2" {
+		t.Errorf("title changed to %q by a failed rename", lecture.Title)
+	}
+	if course, err = ParseCourseYaml(dir); err != nil || course.Lectures[1].Directory != "week-2" {
+		t.Errorf("course after a failed rename: %+v, %v", course, err)
+	}
+}
@@ -0,0 +1,120 @@
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// BlockIndex returns the position of the content block ref names, either
+// its number counting from 1 or its title
+func (lecture *LectureYaml) BlockIndex(ref string) (int, error) {
+	if n, err := strconv.Atoi(ref); err == nil {
+		if n < 1 || n > len(lecture.ContentBlocks) {
+			return -1, fmt.Errorf("no content block %d, the lecture has %d", n, len(lecture.ContentBlocks))
+		}
+		return n - 1, nil
+	}
+	found := -1
+	for i, cb := range lecture.ContentBlocks {
+		if !strings.EqualFold(cb.Title, ref) {
+			continue
+		}
+		if found >= 0 {
+			return -1, fmt.Errorf("more than one content block is titled %q, use its number instead", ref)
+		}
+		found = i
+	}
+	if found < 0 {
+		return -1, fmt.Errorf("no content block titled %q", ref)
+	}
+	return found, nil
+}
+
+// MoveLecture moves the lecture in directory dir to just before the lecture
+// in directory target, or just after it when after is set
+func (c *CourseYaml) MoveLecture(dir, target string, after bool) error {
+	from, err := c.LectureIndex(dir)
+	if err != nil {
+		return err
+	}
+	to, err := c.LectureIndex(target)
+	if err != nil {
+		return err
+	}
+	return c.ReorderLectures(moveOrder(len(c.Lectures), from, to, after))
+}
+
+// MoveBlock moves the content block block to just before the block target,
+// or just after it when after is set. Blocks are named as for BlockIndex.
+func (lecture *LectureYaml) MoveBlock(block, target string, after bool) error {
+	from, err := lecture.BlockIndex(block)
+	if err != nil {
+		return err
+	}
+	to, err := lecture.BlockIndex(target)
+	if err != nil {
+		return err
+	}
+	return lecture.ReorderBlocks(moveOrder(len(lecture.ContentBlocks), from, to, after))
+}
+
+// ReorderLectures puts the lectures in a new order, given as the position
+// each one had before
+func (c *CourseYaml) ReorderLectures(order []int) error {
+	lectures, err := reorder(c.Lectures, order)
+	if err != nil {
+		return err
+	}
+	c.Lectures = lectures
+	return nil
+}
+
+// ReorderBlocks puts the content blocks in a new order, given as the
+// position each one had before
+func (lecture *LectureYaml) ReorderBlocks(order []int) error {
+	blocks, err := reorder(lecture.ContentBlocks, order)
+	if err != nil {
+		return err
+	}
+	lecture.ContentBlocks = blocks
+	return nil
+}
+
+// moveOrder is the order of n items after the one at from is moved next to
+// the one at to
+func moveOrder(n, from, to int, after bool) []int {
+	order := make([]int, 0, n)
+	for i := 0; i < n; i++ {
+		switch {
+		case from == to:
+			order = append(order, i)
+		case i == from:
+		case i == to && after:
+			order = append(order, i, from)
+		case i == to:
+			order = append(order, from, i)
+		default:
+			order = append(order, i)
+		}
+	}
+	return order
+}
+
+// reorder returns the items of s in the given order, which must use every
+// position of s once
+func reorder[T any](s []T, order []int) ([]T, error) {
+	if len(order) != len(s) {
+		return nil, fmt.Errorf("the order has %d positions for %d items", len(order), len(s))
+	}
+	seen := make([]bool, len(s))
+	out := make([]T, len(s))
+	for i, j := range order {
+		if j < 0 || j >= len(s) || seen[j] {
+			return nil, fmt.Errorf("the order must use every position from 0 to %d once", len(s)-1)
+		}
+		seen[j] = true
+		out[i] = s[j]
+	}
+	return out, nil
+}
@@ -0,0 +1,61 @@
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMoveLecture(t *testing.T) {
+	course := &CourseYaml{Lectures: []CourseLectureYaml{
+		{Directory: "a"}, {Directory: "b"}, {Directory: "c"}, {Directory: "d"},
+	}}
+	dirs := func() string {
+		var out []string
+		for _, cl := range course.Lectures {
+			out = append(out, cl.Directory)
+		}
+		return strings.Join(out, " ")
+	}
+
+	for _, tc := range []struct {
+		dir, target string
+		after       bool
+		want        string
+	}{
+		{"d", "b", false, "a d b c"},
+		{"a", "c", true, "d b c a"},
+		{"a", "d", false, "a d b c"},
+		{"b", "b", true, "a d b c"},
+	} {
+		if err := course.MoveLecture(tc.dir, tc.target, tc.after); err != nil {
+			t.Fatal(err)
+		}
+		if got := dirs(); got != tc.want {
+			t.Errorf("moving %s next to %s gave %s, want %s", tc.dir, tc.target, got, tc.want)
+		}
+	}
+	if err := course.MoveLecture("x", "a", false); err == nil {
+		t.Error("expected an error for a lecture not in the course")
+	}
+}
+
+func TestMoveBlock(t *testing.T) {
+	lecture := &LectureYaml{ContentBlocks: []ContentBlockYaml{
+		{Title: "Notes"}, {Title: "Video"}, {Title: "Quiz"}, {Title: "quiz"},
+	}}
+	if err := lecture.MoveBlock("video", "1", false); err != nil {
+		t.Fatal(err)
+	}
+	if lecture.ContentBlocks[0].Title != "Video" || lecture.ContentBlocks[1].Title != "Notes" {
+		t.Errorf("blocks are %+v", lecture.ContentBlocks)
+	}
+	if err := lecture.MoveBlock("Quiz", "1", false); err == nil {
+		t.Error("expected an error for a title shared by two blocks")
+	}
+	if err := lecture.MoveBlock("5", "1", false); err == nil {
+		t.Error("expected an error for a block number out of range")
+	}
+	if err := lecture.ReorderBlocks([]int{0, 0, 1, 2}); err == nil {
+		t.Error("expected an error for an order using a block twice")
+	}
+}
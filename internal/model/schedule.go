@@ -0,0 +1,218 @@
+package model
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+const dateLayout = "2006-01-02"
+
+// ParseWeekdays parses a comma separated list of days such as "Tue,Thu" or
+// "monday, wednesday"
+func ParseWeekdays(s string) ([]time.Weekday, error) {
+	var days []time.Weekday
+	seen := map[time.Weekday]bool{}
+	for _, part := range strings.Split(s, ",") {
+		name := strings.ToLower(strings.TrimSpace(part))
+		if name == "" {
+			continue
+		}
+		found := false
+		for d := time.Sunday; d <= time.Saturday; d++ {
+			full := strings.ToLower(d.String())
+			if name == full || (len(name) >= 2 && strings.HasPrefix(full, name)) {
+				if !seen[d] {
+					days = append(days, d)
+					seen[d] = true
+				}
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown day %q", strings.TrimSpace(part))
+		}
+	}
+	if len(days) == 0 {
+		return nil, fmt.Errorf("no days given")
+	}
+	return days, nil
+}
+
+// ParseClock parses a time of day such as "09:00" or "14:30"
+func ParseClock(s string) (hour, minute int, err error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid time of day %q, use HH:MM such as 09:00", s)
+	}
+	return t.Hour(), t.Minute(), nil
+}
+
+// SkipDate is a day, or a range of days, without lectures such as a holiday
+// or a break
+type SkipDate struct {
+	Name string `yaml:"name,omitempty"`
+	Date string `yaml:"date,omitempty"`
+	From string `yaml:"from,omitempty"`
+	To   string `yaml:"to,omitempty"`
+}
+
+// UnmarshalYAML accepts either a bare date or a mapping with a date or a
+// from/to range
+func (s *SkipDate) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var date string
+	if err := unmarshal(&date); err == nil {
+		*s = SkipDate{Date: date}
+		return nil
+	}
+	type plain SkipDate
+	return unmarshal((*plain)(s))
+}
+
+// Days returns the dates ("2006-01-02") covered by the skip date
+func (s SkipDate) Days() ([]string, error) {
+	if s.Date != "" {
+		d, err := time.Parse(dateLayout, s.Date)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date %q, use YYYY-MM-DD", s.Date)
+		}
+		return []string{d.Format(dateLayout)}, nil
+	}
+	from, err := time.Parse(dateLayout, s.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid from date %q, use YYYY-MM-DD", s.From)
+	}
+	to, err := time.Parse(dateLayout, s.To)
+	if err != nil {
+		return nil, fmt.Errorf("invalid to date %q, use YYYY-MM-DD", s.To)
+	}
+	if to.Before(from) {
+		return nil, fmt.Errorf("range %s to %s ends before it starts", s.From, s.To)
+	}
+	var days []string
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		days = append(days, d.Format(dateLayout))
+	}
+	return days, nil
+}
+
+// ReadSkipDates reads a yaml list of skip dates, each either a date or a
+// mapping like {name: Fall break, from: 2024-10-14, to: 2024-10-18}
+func ReadSkipDates(path string) ([]SkipDate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var dates []SkipDate
+	if err := yaml.Unmarshal(data, &dates); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return dates, nil
+}
+
+// MeetingPattern describes when a class meets, such as Tuesdays and
+// Thursdays at 09:00 in New York
+type MeetingPattern struct {
+	Days     []time.Weekday
+	Hour     int
+	Minute   int
+	Location *time.Location
+	Skip     []SkipDate
+}
+
+// Meetings returns the first n meetings on or after the day of start, skipping
+// the skip dates. Meetings after end are not returned, so fewer than n may
+// come back when end is set.
+func (p MeetingPattern) Meetings(start, end time.Time, n int) ([]time.Time, error) {
+	skip := map[string]string{}
+	for _, s := range p.Skip {
+		days, err := s.Days()
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range days {
+			skip[d] = s.Name
+		}
+	}
+	meets := map[time.Weekday]bool{}
+	for _, d := range p.Days {
+		meets[d] = true
+	}
+	if len(meets) == 0 {
+		return nil, fmt.Errorf("no meeting days given")
+	}
+
+	loc := p.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	start = start.In(loc)
+	day := time.Date(start.Year(), start.Month(), start.Day(), p.Hour, p.Minute, 0, 0, loc)
+
+	var meetings []time.Time
+	for len(meetings) < n {
+		if !end.IsZero() && day.After(end) {
+			break
+		}
+		if _, skipped := skip[day.Format(dateLayout)]; meets[day.Weekday()] && !skipped {
+			meetings = append(meetings, day)
+		}
+		day = time.Date(day.Year(), day.Month(), day.Day()+1, p.Hour, p.Minute, 0, 0, loc)
+	}
+	return meetings, nil
+}
+
+// termBounds returns the start and end of the course. A date without a time
+// for the end date includes the whole day.
+func (c CourseYaml) termBounds(loc *time.Location) (start, end time.Time, err error) {
+	if c.StartDate != "" {
+		if start, err = ParseTime(c.StartDate, loc); err != nil {
+			return start, end, fmt.Errorf("start_date: %w", err)
+		}
+	}
+	if c.EndDate != "" {
+		if end, err = ParseTime(c.EndDate, loc); err != nil {
+			return start, end, fmt.Errorf("end_date: %w", err)
+		}
+		if len(c.EndDate) == len(dateLayout) {
+			end = end.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		}
+	}
+	return start, end, nil
+}
+
+// Schedule returns an available_at time for every lecture, in order, using
+// the meetings of p between the course start and end dates. It fails if the
+// course has no start date or the term has fewer meetings than lectures.
+func (c CourseYaml) Schedule(p MeetingPattern) ([]time.Time, error) {
+	start, end, err := c.termBounds(p.Location)
+	if err != nil {
+		return nil, err
+	}
+	if start.IsZero() {
+		return nil, fmt.Errorf("the course has no start_date to schedule from")
+	}
+	meetings, err := p.Meetings(start, end, len(c.Lectures))
+	if err != nil {
+		return nil, err
+	}
+	if len(meetings) < len(c.Lectures) {
+		return nil, fmt.Errorf(
+			"only %s between %s and %s for %d lectures",
+			pluralize(len(meetings), "meeting"), c.StartDate, c.EndDate, len(c.Lectures),
+		)
+	}
+	return meetings, nil
+}
+
+func pluralize(n int, word string) string {
+	if n == 1 {
+		return "1 " + word
+	}
+	return strconv.Itoa(n) + " " + word + "s"
+}
@@ -0,0 +1,56 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestParseWeekdays(t *testing.T) {
+	days, err := ParseWeekdays("Tue, thursday,TU")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(days) != 2 || days[0] != time.Tuesday || days[1] != time.Thursday {
+		t.Errorf("unexpected days %v", days)
+	}
+	if _, err := ParseWeekdays("Tue,Funday"); err == nil {
+		t.Error("expected an error for an unknown day")
+	}
+}
+
+func TestSchedule(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("time zone data not available")
+	}
+
+	var skip []SkipDate
+	err = yaml.Unmarshal([]byte("- 2024-09-05\n- {name: Break, from: 2024-09-10, to: 2024-09-12}\n"), &skip)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	course := CourseYaml{
+		StartDate: "2024-09-03",
+		EndDate:   "2024-09-30",
+		Lectures:  []CourseLectureYaml{{Directory: "a"}, {Directory: "b"}, {Directory: "c"}},
+	}
+	pattern := MeetingPattern{Days: []time.Weekday{time.Tuesday, time.Thursday}, Hour: 9, Location: ny, Skip: skip}
+	times, err := course.Schedule(pattern)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"2024-09-03T09:00:00-04:00", "2024-09-17T09:00:00-04:00", "2024-09-19T09:00:00-04:00"}
+	for i, w := range want {
+		if got := times[i].Format(time.RFC3339); got != w {
+			t.Errorf("lecture %d: got %s, want %s", i, got, w)
+		}
+	}
+
+	course.EndDate = "2024-09-17"
+	if _, err := course.Schedule(pattern); err == nil {
+		t.Error("expected an error when the term has too few meetings")
+	}
+}
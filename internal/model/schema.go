@@ -0,0 +1,245 @@
+package model
+
+import (
+	"reflect"
+	"strings"
+)
+
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema is the subset of JSON Schema needed to describe the course and
+// lecture yaml files for editors such as yaml-language-server
+type JSONSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	Ref                  string                 `json:"$ref,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	Const                string                 `json:"const,omitempty"`
+	Enum                 []string               `json:"enum,omitempty"`
+	Examples             []string               `json:"examples,omitempty"`
+	Minimum              *int                   `json:"minimum,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties *bool                  `json:"additionalProperties,omitempty"`
+	AnyOf                []*JSONSchema          `json:"anyOf,omitempty"`
+	AllOf                []*JSONSchema          `json:"allOf,omitempty"`
+	If                   *JSONSchema            `json:"if,omitempty"`
+	Then                 *JSONSchema            `json:"then,omitempty"`
+	Defs                 map[string]*JSONSchema `json:"$defs,omitempty"`
+}
+
+// schemaField holds the extra information about a yaml field that cannot be
+// recovered from the Go type alone
+type schemaField struct {
+	Description string
+	Enum        []string
+	Examples    []string
+	Format      string
+	Minimum     *int
+}
+
+func intPtr(i int) *int { return &i }
+
+var schemaFields = map[string]schemaField{
+	"CourseYaml.course_type":                  {Description: "How the course is offered", Enum: CourseTypes[:]},
+	"CourseYaml.end_date":                     {Description: "Last day of the course"},
+	"CourseYaml.id":                           {Description: "Assigned by the server. Do not edit"},
+	"CourseYaml.last_commit_sha":              {Description: "Assigned by the CLI. Do not edit"},
+	"CourseYaml.lectures":                     {Description: "Lectures in the order they appear in the course"},
+	"CourseYaml.slug":                         {Description: "Course slug as it appears in the Jupyteach URL"},
+	"CourseYaml.start_date":                   {Description: "First day of the course"},
+	"CourseYaml.cli_timezone":                 {Description: "IANA time zone of local times in the course, e.g. America/New_York (default the computer's time zone)"},
+	"CourseYaml.cli_directory_word_separator": {Description: "Separator used between words when naming lecture directories (default -)"},
+	"CourseYaml.cli_directory_max_length":     {Description: "Longest lecture directory name, in characters (default no limit)", Minimum: intPtr(0)},
+	"CourseYaml.cli_directory_keep_unicode":   {Description: "Keep non-ASCII letters in lecture directory names instead of transliterating them"},
+	"CourseYaml.cli_directory_replacements":   {Description: "Text replaced in lecture titles before naming their directory, e.g. {\"&\": and}"},
+	"CourseYaml.cli_strip_notebook_outputs":   {Description: "Remove outputs and execution counts from notebooks when pushing"},
+	"CourseLectureYaml.directory":             {Description: "Directory holding the lecture's _lecture.yml. Must be the slugified lecture title"},
+	"CourseLectureYaml.available_at":          {Description: "When the lecture becomes visible to students: RFC3339, a local time in the course timezone (2024-09-03 09:00) or an expression like start+2w 9am"},
+	"CourseLectureYaml.course_lecture_id":     {Description: "Assigned by the server. Do not edit"},
+	"CourseLectureYaml.lecture_id":            {Description: "Assigned by the server. Do not edit"},
+	"LectureYaml.content_blocks":              {Description: "Content blocks in the order they appear in the lecture"},
+	"LectureYaml.course_lecture_id":           {Description: "Assigned by the server. Do not edit"},
+	"LectureYaml.lecture_id":                  {Description: "Assigned by the server. Do not edit"},
+	"ContentBlockYaml.type":                   {Description: "Kind of content block", Enum: ContentBlockTypes[:]},
+	"ContentBlockYaml.filename":               {Description: "Path of the notebook, markdown or markdown quiz file, relative to the lecture directory"},
+	"ContentBlockYaml.url":                    {Description: "Link target, or video URL when not hosted on YouTube or Vimeo", Format: "uri"},
+	"ContentBlockYaml.content_block_id":       {Description: "Assigned by the server. Do not edit"},
+	"ContentBlockYaml.lecture_content_id":     {Description: "Assigned by the server. Do not edit"},
+	"ContentBlockYaml.n_uploads":              {Minimum: intPtr(0)},
+	"ContentBlockYaml.cli_due_at":             {Description: "When the quiz or uploads of the block are due: RFC3339, a local time in the course timezone (2024-09-05 23:59) or an expression like start+3d 23:59"},
+	"Quiz.quiz_id":                            {Description: "Assigned by the server. Do not edit"},
+	"Quiz.max_attempts":                       {Description: "Number of attempts a student gets", Minimum: intPtr(1)},
+	"Question.id":                             {Description: "Assigned by the server. Do not edit"},
+	"Question.question_type":                  {Description: "Kind of question", Examples: QuestionTypes[:]},
+	"Question.difficulty":                     {Enum: Difficulties[:]},
+	"Question.solution":                       {Description: "Correct answer. For multiple_selection a list of options such as [\"a\", \"b\"]"},
+	"Question.points":                         {Minimum: intPtr(1)},
+	"Question.options":                        {Description: "Choices shown for selection questions"},
+}
+
+// schemaRequired lists the fields that must be present for each type
+var schemaRequired = map[string][]string{
+	"CourseYaml":        {"slug"},
+	"CourseLectureYaml": {"directory"},
+	"LectureYaml":       {"title", "content_blocks"},
+	"ContentBlockYaml":  {"type"},
+	"Quiz":              {"questions"},
+	"Question":          {"question_type", "question_text", "points"},
+}
+
+// videoSourceFields maps each entry of VideoSources to the field that holds it
+var videoSourceFields = map[string]string{
+	"youtube": "youtube_video_id",
+	"vimeo":   "vimeo_video_id",
+	"url":     "url",
+}
+
+func yamlFieldName(f reflect.StructField) string {
+	tag := f.Tag.Get("yaml")
+	if tag == "-" {
+		return ""
+	}
+	name := strings.Split(tag, ",")[0]
+	if name == "" {
+		name = strings.ToLower(f.Name)
+	}
+	return name
+}
+
+type schemaBuilder struct {
+	defs map[string]*JSONSchema
+}
+
+func (b *schemaBuilder) forType(t reflect.Type) *JSONSchema {
+	switch t.Kind() {
+	case reflect.String:
+		return &JSONSchema{Type: "string"}
+	case reflect.Int, reflect.Int64, reflect.Int32:
+		return &JSONSchema{Type: "integer"}
+	case reflect.Bool:
+		return &JSONSchema{Type: "boolean"}
+	case reflect.Slice, reflect.Array:
+		return &JSONSchema{Type: "array", Items: b.forType(t.Elem())}
+	case reflect.Map:
+		return &JSONSchema{Type: "object"}
+	case reflect.Ptr:
+		return b.forType(t.Elem())
+	case reflect.Struct:
+		name := t.Name()
+		if _, ok := b.defs[name]; !ok {
+			// register before recursing so self references terminate
+			b.defs[name] = nil
+			b.defs[name] = b.forStruct(t)
+		}
+		return &JSONSchema{Ref: "#/$defs/" + name}
+	}
+	return &JSONSchema{}
+}
+
+func (b *schemaBuilder) forStruct(t reflect.Type) *JSONSchema {
+	closed := false
+	s := &JSONSchema{
+		Type:                 "object",
+		Properties:           map[string]*JSONSchema{},
+		Required:             schemaRequired[t.Name()],
+		AdditionalProperties: &closed,
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := yamlFieldName(f)
+		if name == "" || !f.IsExported() {
+			continue
+		}
+		prop := b.forType(f.Type)
+		if extra, ok := schemaFields[t.Name()+"."+name]; ok {
+			if prop.Ref != "" {
+				// $ref siblings are ignored by older drafts, so wrap it
+				prop = &JSONSchema{AllOf: []*JSONSchema{prop}}
+			}
+			prop.Description = extra.Description
+			prop.Enum = extra.Enum
+			prop.Examples = extra.Examples
+			prop.Format = extra.Format
+			prop.Minimum = extra.Minimum
+		}
+		s.Properties[name] = prop
+	}
+	return s
+}
+
+// requireWhen returns an if/then clause that applies `then` to objects whose
+// `key` field equals `value`
+func requireWhen(key, value string, then *JSONSchema) *JSONSchema {
+	return &JSONSchema{
+		If: &JSONSchema{
+			Properties: map[string]*JSONSchema{key: {Const: value}},
+			Required:   []string{key},
+		},
+		Then: then,
+	}
+}
+
+func requireWhenType(blockType string, then *JSONSchema) *JSONSchema {
+	return requireWhen("type", blockType, then)
+}
+
+func (b *schemaBuilder) addContentBlockRules() {
+	cb := b.defs["ContentBlockYaml"]
+	if cb == nil {
+		return
+	}
+	videoSources := make([]*JSONSchema, 0, len(VideoSources))
+	for _, source := range VideoSources {
+		videoSources = append(videoSources, &JSONSchema{Required: []string{videoSourceFields[source]}})
+	}
+	cb.AllOf = []*JSONSchema{
+		requireWhenType("notebook", &JSONSchema{Required: []string{"filename"}}),
+		requireWhenType("markdown", &JSONSchema{Required: []string{"filename"}}),
+		requireWhenType("link", &JSONSchema{Required: []string{"url"}}),
+		requireWhenType("quiz", &JSONSchema{AnyOf: []*JSONSchema{{Required: []string{"quiz"}}, {Required: []string{"filename"}}}}),
+		requireWhenType("video", &JSONSchema{AnyOf: videoSources}),
+	}
+}
+
+func (b *schemaBuilder) addQuestionRules() {
+	q := b.defs["Question"]
+	if q == nil {
+		return
+	}
+	withOptions := &JSONSchema{Required: []string{"options", "solution"}}
+	q.AllOf = []*JSONSchema{
+		requireWhen("question_type", "single_selection", withOptions),
+		requireWhen("question_type", "multiple_selection", withOptions),
+		requireWhen("question_type", "code", &JSONSchema{Required: []string{"solution", "test_code"}}),
+		requireWhen("question_type", "fill_in_blank", &JSONSchema{Required: []string{"starting_code", "solution"}}),
+	}
+}
+
+func buildSchema(title string, v interface{}) *JSONSchema {
+	b := &schemaBuilder{defs: map[string]*JSONSchema{}}
+	t := reflect.TypeOf(v)
+	root := b.forStruct(t)
+	b.addContentBlockRules()
+	b.addQuestionRules()
+
+	root.Schema = jsonSchemaDraft
+	root.Title = title
+	if len(b.defs) > 0 {
+		root.Defs = b.defs
+	}
+	return root
+}
+
+// CourseSchema returns the JSON Schema describing `_course.yml`
+func CourseSchema() *JSONSchema {
+	return buildSchema("Jupyteach _course.yml", CourseYaml{})
+}
+
+// LectureSchema returns the JSON Schema describing `_lecture.yml`
+func LectureSchema() *JSONSchema {
+	return buildSchema("Jupyteach _lecture.yml", LectureYaml{})
+}
@@ -0,0 +1,71 @@
+package model
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// whenType returns the then clause the content block schema applies to
+// blocks of blockType
+func whenType(t *testing.T, cb *JSONSchema, blockType string) *JSONSchema {
+	t.Helper()
+	for _, rule := range cb.AllOf {
+		if rule.If != nil && rule.If.Properties["type"] != nil && rule.If.Properties["type"].Const == blockType {
+			return rule.Then
+		}
+	}
+	t.Fatalf("no rule for %s blocks", blockType)
+	return nil
+}
+
+func TestSchemas(t *testing.T) {
+	course, lecture := CourseSchema(), LectureSchema()
+	for _, s := range []*JSONSchema{course, lecture} {
+		if s.Schema != jsonSchemaDraft {
+			t.Errorf("%s: $schema = %q", s.Title, s.Schema)
+		}
+		if _, err := json.Marshal(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := course.Properties["course_type"].Enum; !reflect.DeepEqual(got, CourseTypes[:]) {
+		t.Errorf("course_type enum = %v, want %v", got, CourseTypes)
+	}
+	if got := course.Properties["slug"].Description; got != "Course slug as it appears in the Jupyteach URL" {
+		t.Errorf("slug description = %q", got)
+	}
+	if !reflect.DeepEqual(course.Required, []string{"slug"}) {
+		t.Errorf("course required = %v", course.Required)
+	}
+
+	cb := lecture.Defs["ContentBlockYaml"]
+	if cb == nil {
+		t.Fatal("lecture schema has no ContentBlockYaml definition")
+	}
+	if got := cb.Properties["type"].Enum; !reflect.DeepEqual(got, ContentBlockTypes[:]) {
+		t.Errorf("type enum = %v, want %v", got, ContentBlockTypes)
+	}
+	if m := cb.Properties["n_uploads"].Minimum; m == nil || *m != 0 {
+		t.Errorf("n_uploads minimum = %v, want 0", m)
+	}
+	if m := lecture.Defs["Quiz"].Properties["max_attempts"].Minimum; m == nil || *m != 1 {
+		t.Errorf("max_attempts minimum = %v, want 1", m)
+	}
+
+	for blockType, field := range map[string]string{"notebook": "filename", "markdown": "filename", "link": "url"} {
+		if got := whenType(t, cb, blockType).Required; !reflect.DeepEqual(got, []string{field}) {
+			t.Errorf("%s blocks require %v, want [%s]", blockType, got, field)
+		}
+	}
+	video := whenType(t, cb, "video")
+	if len(video.AnyOf) != len(VideoSources) {
+		t.Fatalf("video blocks have %d sources, want %d", len(video.AnyOf), len(VideoSources))
+	}
+	for i, source := range VideoSources {
+		if got := video.AnyOf[i].Required; !reflect.DeepEqual(got, []string{videoSourceFields[source]}) {
+			t.Errorf("video source %s requires %v", source, got)
+		}
+	}
+}
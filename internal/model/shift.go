@@ -0,0 +1,160 @@
+package model
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DateChange is a date of the course moved by ShiftDates
+type DateChange struct {
+	// File is the course file holding the date, relative to the course
+	// directory
+	File  string
+	Field string
+	Old   string
+	New   string
+}
+
+// DateShift is the course with every date moved to a new term
+type DateShift struct {
+	// Days is how far lecture and due dates move
+	Days int
+
+	Course *CourseYaml
+
+	// Lectures holds the lectures whose due dates moved, by directory
+	Lectures map[string]*LectureYaml
+
+	Changes []DateChange
+
+	// Relative lists the times left as they are because they are counted
+	// from today, like "next tue 9am"
+	Relative []DateChange
+}
+
+var datePrefix = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}`)
+
+// ShiftDates moves the start_date of the course to the day of start, and its
+// end_date, lecture available_at times and block cli_due_at times by the same
+// number of days. Times keep their time of day in the course timezone.
+//
+// With sameWeekday the dates other than start_date move by the smallest whole
+// number of weeks that doesn't bring them earlier relative to the new start,
+// so lectures stay on the days the class meets. Expressions counted from
+// start or end, like start+2w, follow the new course dates.
+func (c CourseYaml) ShiftDates(dirname string, start time.Time, sameWeekday bool) (*DateShift, error) {
+	if c.StartDate == "" {
+		return nil, fmt.Errorf("the course has no start_date to shift from")
+	}
+	r, err := c.TimeResolver(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	start = start.In(r.Location)
+	from := time.Date(r.Start.Year(), r.Start.Month(), r.Start.Day(), 0, 0, 0, 0, time.UTC)
+	to := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	startDays := int(to.Sub(from).Hours() / 24)
+	days := startDays
+	if rem := ((days % 7) + 7) % 7; sameWeekday && rem != 0 {
+		days += 7 - rem
+	}
+
+	shifted := c
+	shifted.Lectures = append([]CourseLectureYaml(nil), c.Lectures...)
+	s := &DateShift{Days: days, Course: &shifted, Lectures: map[string]*LectureYaml{}}
+
+	move := func(file, field string, value *string, n int) error {
+		if *value == "" {
+			return nil
+		}
+		moved, ok, err := shiftTime(*value, n, r)
+		if err != nil {
+			return fmt.Errorf("%s in %s: %w", field, file, err)
+		}
+		change := DateChange{File: file, Field: field, Old: *value, New: moved}
+		if !ok {
+			if res, _ := r.Resolve(*value); res.RelativeToNow {
+				s.Relative = append(s.Relative, change)
+			}
+			return nil
+		}
+		s.Changes = append(s.Changes, change)
+		*value = moved
+		return nil
+	}
+
+	if err := move("_course.yml", "start_date", &shifted.StartDate, startDays); err != nil {
+		return nil, err
+	}
+	if err := move("_course.yml", "end_date", &shifted.EndDate, days); err != nil {
+		return nil, err
+	}
+	for i := range shifted.Lectures {
+		cl := &shifted.Lectures[i]
+		if err := move("_course.yml", "available_at of "+cl.Directory, &cl.AvailableAt, days); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, cl := range c.Lectures {
+		file := filepath.Join(cl.Directory, "_lecture.yml")
+		lecture, err := ParseLectureYaml(filepath.Join(dirname, file))
+		if err != nil {
+			return nil, err
+		}
+		moved := false
+		for i := range lecture.ContentBlocks {
+			cb := &lecture.ContentBlocks[i]
+			if cb.DueAt == "" {
+				continue
+			}
+			name := cb.Title
+			if name == "" {
+				name = fmt.Sprintf("content block %d", i+1)
+			}
+			before := len(s.Changes)
+			if err := move(file, "cli_due_at of "+name, &cb.DueAt, days); err != nil {
+				return nil, err
+			}
+			moved = moved || len(s.Changes) > before
+		}
+		if moved {
+			s.Lectures[cl.Directory] = lecture
+		}
+	}
+	return s, nil
+}
+
+// shiftTime moves the time s, as written in a course file, by days, keeping
+// the way it is written. ok is false for expressions counted from start, end
+// or today, which are returned as they are.
+func shiftTime(s string, days int, r *TimeResolver) (moved string, ok bool, err error) {
+	trimmed := strings.TrimSpace(s)
+	if t, err := time.Parse(time.RFC3339, trimmed); err == nil {
+		t = t.In(r.Location).AddDate(0, 0, days)
+		if strings.HasSuffix(trimmed, "Z") {
+			t = t.UTC()
+		}
+		return t.Format(time.RFC3339), true, nil
+	}
+	for _, layout := range timeLayouts[1:] {
+		if t, err := time.Parse(layout, trimmed); err == nil {
+			return t.AddDate(0, 0, days).Format(layout), true, nil
+		}
+	}
+	if _, err := r.Resolve(trimmed); err != nil {
+		return "", false, err
+	}
+	if date := datePrefix.FindString(trimmed); date != "" {
+		d, err := time.Parse(dateLayout, date)
+		if err != nil {
+			return "", false, err
+		}
+		return d.AddDate(0, 0, days).Format(dateLayout) + trimmed[len(date):], true, nil
+	}
+	return s, false, nil
+}
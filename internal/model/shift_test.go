@@ -0,0 +1,68 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestShiftDates(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"a/_lecture.yml": `title: A
+content_blocks:
+- type: quiz
+  title: Warmup
+  cli_due_at: 2024-09-05T23:59:00-04:00
+- type: markdown
+  filename: notes.md
+`,
+		"b/_lecture.yml": "title: B\ncontent_blocks: []\n",
+	})
+	course := CourseYaml{
+		Timezone:  "America/New_York",
+		StartDate: "2024-09-02",
+		EndDate:   "2024-12-13",
+		Lectures: []CourseLectureYaml{
+			{Directory: "a", AvailableAt: "2024-09-03T13:00:00Z"},
+			{Directory: "b", AvailableAt: "start+1w 9am"},
+		},
+	}
+	ny, _ := time.LoadLocation("America/New_York")
+
+	// 2024-09-02 is a Monday and 2025-01-14 a Tuesday, 134 days later
+	s, err := course.ShiftDates(dir, time.Date(2025, 1, 14, 0, 0, 0, 0, ny), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Days != 134 || s.Course.StartDate != "2025-01-14" || s.Course.EndDate != "2025-04-26" {
+		t.Errorf("unexpected shift of %d days to %s - %s", s.Days, s.Course.StartDate, s.Course.EndDate)
+	}
+	// 09:00 in New York stays 09:00 after daylight saving time ends
+	if got := s.Course.Lectures[0].AvailableAt; got != "2025-01-15T14:00:00Z" {
+		t.Errorf("available_at = %s", got)
+	}
+	if got := s.Course.Lectures[1].AvailableAt; got != "start+1w 9am" {
+		t.Errorf("expression from start changed to %s", got)
+	}
+	if course.StartDate != "2024-09-02" || course.Lectures[0].AvailableAt != "2024-09-03T13:00:00Z" {
+		t.Error("ShiftDates changed the original course")
+	}
+	lecture, ok := s.Lectures["a"]
+	if !ok || len(s.Lectures) != 1 {
+		t.Fatalf("expected only lecture a to change, got %v", s.Lectures)
+	}
+	if got := lecture.ContentBlocks[0].DueAt; got != "2025-01-17T23:59:00-05:00" {
+		t.Errorf("cli_due_at = %s", got)
+	}
+	if len(s.Changes) != 4 {
+		t.Errorf("expected 4 changes, got %+v", s.Changes)
+	}
+
+	s, err = course.ShiftDates(dir, time.Date(2025, 1, 14, 0, 0, 0, 0, ny), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Days != 140 || s.Course.StartDate != "2025-01-14" || s.Course.Lectures[0].AvailableAt != "2025-01-21T14:00:00Z" {
+		t.Errorf("unexpected same weekday shift of %d days: %+v", s.Days, s.Changes)
+	}
+}
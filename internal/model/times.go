@@ -0,0 +1,314 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Location returns the time zone of the course, or the local time zone of the
+// computer when _course.yml sets none
+func (c CourseYaml) Location() (*time.Location, error) {
+	if c.Timezone == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone %q, use an IANA name such as America/New_York", c.Timezone)
+	}
+	return loc, nil
+}
+
+// TimeResolver turns the times written in course files into instants. Local
+// times are taken to be in Location, "start" and "end" refer to the course
+// start_date and end_date, and "now", "today", "tomorrow" and weekdays are
+// relative to Now.
+type TimeResolver struct {
+	Location *time.Location
+	Now      time.Time
+	Start    time.Time
+	End      time.Time
+}
+
+// TimeResolver returns a resolver for the times of the course, with relative
+// times counted from now
+func (c CourseYaml) TimeResolver(now time.Time) (*TimeResolver, error) {
+	r, err := c.lenientTimeResolver(now)
+	if err != nil {
+		return nil, err
+	}
+	if c.StartDate != "" && r.Start.IsZero() {
+		_, err := ParseTime(c.StartDate, r.Location)
+		return nil, fmt.Errorf("start_date: %w", err)
+	}
+	if c.EndDate != "" && r.End.IsZero() {
+		_, err := ParseTime(c.EndDate, r.Location)
+		return nil, fmt.Errorf("end_date: %w", err)
+	}
+	return r, nil
+}
+
+// lenientTimeResolver is TimeResolver for callers that must not fail on term
+// dates push never used to validate: a start_date or end_date that can't be
+// read is left unset, so only times counted from it fail. It fails only for
+// an unknown cli_timezone.
+func (c CourseYaml) lenientTimeResolver(now time.Time) (*TimeResolver, error) {
+	loc, err := c.Location()
+	if err != nil {
+		return nil, err
+	}
+	r := &TimeResolver{Location: loc, Now: now.In(loc)}
+	if c.StartDate != "" {
+		r.Start, _ = ParseTime(c.StartDate, loc)
+	}
+	if c.EndDate != "" {
+		r.End, _ = ParseTime(c.EndDate, loc)
+	}
+	return r, nil
+}
+
+// ResolvedTime is a time from a course file along with how it was written
+type ResolvedTime struct {
+	Time time.Time
+
+	// HasZone is true when the time was written with a UTC offset and so
+	// does not depend on any time zone setting
+	HasZone bool
+
+	// RelativeToNow is true when the time depends on the day it is resolved,
+	// like "tomorrow" or "next tue 9am"
+	RelativeToNow bool
+
+	// Note explains why a local time is ambiguous, such as a time skipped or
+	// repeated by a daylight saving change
+	Note string
+}
+
+var (
+	baseExpr   = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2}|next\s+[a-z]+|[a-z]+)`)
+	offsetExpr = regexp.MustCompile(`^\s*([+-]?)\s*(\d+)\s*(weeks|week|w|days|day|d|hours|hour|h)`)
+	clockExpr  = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?\s*(am|pm)?$`)
+)
+
+// Parse resolves s, see Resolve
+func (r *TimeResolver) Parse(s string) (time.Time, error) {
+	res, err := r.Resolve(s)
+	return res.Time, err
+}
+
+// Resolve accepts an RFC3339 timestamp, a local date and time such as
+// "2024-09-03 09:00", or an expression made of a base, optional offsets and an
+// optional time of day:
+//
+//	start+2w           two weeks after the course start_date
+//	end-3d 17:00       three days before the end_date at 5pm
+//	2024-09-03 9am     a local date and time
+//	tomorrow 14:30
+//	tue 9am            the coming Tuesday, today included
+//	next tue 9am       the first Tuesday after today
+//
+// Offsets are counted in weeks (w), days (d) or hours (h) and can be chained,
+// as in start+1w2d.
+func (r *TimeResolver) Resolve(s string) (ResolvedTime, error) {
+	s = strings.TrimSpace(s)
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return ResolvedTime{Time: t, HasZone: true}, nil
+	}
+	for _, layout := range timeLayouts[1:] {
+		if t, err := time.Parse(layout, s); err == nil {
+			res := ResolvedTime{}
+			res.Time, res.Note = wallTime(t, r.Location)
+			return res, nil
+		}
+	}
+	res, err := r.resolveExpression(strings.ToLower(s))
+	if err != nil {
+		return res, fmt.Errorf("invalid time %q: %w", s, err)
+	}
+	return res, nil
+}
+
+func (r *TimeResolver) resolveExpression(s string) (ResolvedTime, error) {
+	var res ResolvedTime
+	base := baseExpr.FindString(s)
+	if base == "" {
+		return res, fmt.Errorf("use RFC3339 (2024-09-03T09:00:00-04:00), a local time (2024-09-03 09:00) or an expression like start+2w or next tue 9am")
+	}
+	rest := s[len(base):]
+
+	today := time.Date(r.Now.Year(), r.Now.Month(), r.Now.Day(), 0, 0, 0, 0, r.Location)
+	var t time.Time
+	switch base {
+	case "start", "end":
+		t = r.Start
+		if base == "end" {
+			t = r.End
+		}
+		if t.IsZero() {
+			return res, fmt.Errorf("%s needs the %s_date of the course in _course.yml", base, base)
+		}
+	case "now":
+		t, res.RelativeToNow = r.Now, true
+	case "today":
+		t, res.RelativeToNow = today, true
+	case "tomorrow":
+		t, res.RelativeToNow = today.AddDate(0, 0, 1), true
+	default:
+		if date, err := time.Parse(dateLayout, base); err == nil {
+			t = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, r.Location)
+			break
+		}
+		name := strings.TrimSpace(strings.TrimPrefix(base, "next"))
+		days, err := ParseWeekdays(name)
+		if err != nil || len(days) != 1 || strings.Contains(name, ",") {
+			return res, fmt.Errorf("unknown day or anchor %q, use start, end, now, today, tomorrow, a weekday or a date", base)
+		}
+		ahead := (int(days[0]) - int(today.Weekday()) + 7) % 7
+		if ahead == 0 && strings.HasPrefix(base, "next") {
+			ahead = 7
+		}
+		t, res.RelativeToNow = today.AddDate(0, 0, ahead), true
+	}
+
+	// an offset without a sign, as in "start+1w 2d", takes the sign of the
+	// one before it
+	sign := "+"
+	for {
+		m := offsetExpr.FindStringSubmatch(rest)
+		if m == nil {
+			break
+		}
+		rest = rest[len(m[0]):]
+		if m[1] != "" {
+			sign = m[1]
+		}
+		n, _ := strconv.Atoi(m[2])
+		if sign == "-" {
+			n = -n
+		}
+		switch m[3][0] {
+		case 'w':
+			t = t.AddDate(0, 0, 7*n)
+		case 'd':
+			t = t.AddDate(0, 0, n)
+		case 'h':
+			t = t.Add(time.Duration(n) * time.Hour)
+		}
+	}
+
+	res.Time = t
+	rest = strings.TrimSpace(rest)
+	rest = strings.TrimSpace(strings.TrimPrefix(rest, "at "))
+	if rest == "" {
+		return res, nil
+	}
+	hour, minute, err := parseTimeOfDay(rest)
+	if err != nil {
+		return res, err
+	}
+	res.Time, res.Note = wallTime(time.Date(t.Year(), t.Month(), t.Day(), hour, minute, 0, 0, time.UTC), r.Location)
+	return res, nil
+}
+
+// parseTimeOfDay parses "9am", "9:30 pm", "14:00", "noon" or "midnight". A
+// bare hour like "9" is rejected since it could mean morning or evening.
+func parseTimeOfDay(s string) (hour, minute int, err error) {
+	switch s {
+	case "noon":
+		return 12, 0, nil
+	case "midnight":
+		return 0, 0, nil
+	}
+	m := clockExpr.FindStringSubmatch(s)
+	if m == nil {
+		return 0, 0, fmt.Errorf("invalid time of day %q, use 9am, 9:30pm or 14:00", s)
+	}
+	hour, _ = strconv.Atoi(m[1])
+	if m[2] != "" {
+		minute, _ = strconv.Atoi(m[2])
+	}
+	switch {
+	case m[2] == "" && m[3] == "":
+		return 0, 0, fmt.Errorf("ambiguous time of day %q, write %sam, %spm or %s:00", s, s, s, s)
+	case m[3] != "" && (hour < 1 || hour > 12):
+		return 0, 0, fmt.Errorf("invalid time of day %q, hours go from 1 to 12 with am and pm", s)
+	case hour > 23 || minute > 59:
+		return 0, 0, fmt.Errorf("invalid time of day %q", s)
+	}
+	if m[3] == "pm" && hour != 12 {
+		hour += 12
+	} else if m[3] == "am" && hour == 12 {
+		hour = 0
+	}
+	return hour, minute, nil
+}
+
+// wallTime returns the instant at which clocks in loc show the date and time
+// of the UTC time wall. note is set when daylight saving time skips that wall
+// time, or repeats it, in loc.
+func wallTime(wall time.Time, loc *time.Location) (t time.Time, note string) {
+	t = time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), wall.Second(), 0, loc)
+
+	// try the UTC offsets in use around the wall time
+	var matches []time.Time
+	for _, probe := range []time.Time{wall.Add(-24 * time.Hour), wall, wall.Add(24 * time.Hour)} {
+		_, offset := probe.In(loc).Zone()
+		candidate := wall.Add(-time.Duration(offset) * time.Second)
+		if candidate.In(loc).Format("2006-01-02 15:04:05") != wall.Format("2006-01-02 15:04:05") {
+			continue
+		}
+		duplicate := false
+		for _, m := range matches {
+			duplicate = duplicate || m.Equal(candidate)
+		}
+		if !duplicate {
+			matches = append(matches, candidate)
+		}
+	}
+	switch {
+	case len(matches) == 0:
+		note = fmt.Sprintf("%s does not exist in %s because of daylight saving time, it is taken as %s", wall.Format("2006-01-02 15:04"), loc, t.Format("15:04 MST"))
+	case len(matches) > 1:
+		note = fmt.Sprintf("%s happens twice in %s because of daylight saving time, it is taken as %s", wall.Format("2006-01-02 15:04"), loc, t.Format("15:04 MST"))
+	}
+	return t, note
+}
+
+// NormalizeTimes returns a copy of the course with every available_at
+// resolved to an RFC3339 timestamp, the form jupyteach add writes them in.
+// Times that can't be resolved, and every time when the cli_timezone is
+// unknown, are kept as written for check to report.
+func (c CourseYaml) NormalizeTimes(now time.Time) *CourseYaml {
+	normalized := c
+	r, err := c.lenientTimeResolver(now)
+	if err != nil {
+		return &normalized
+	}
+	normalized.Lectures = make([]CourseLectureYaml, len(c.Lectures))
+	for i, cl := range c.Lectures {
+		if at, ok, err := r.AvailableTime(cl); err == nil && ok {
+			cl.AvailableAt = at.Format(time.RFC3339)
+		}
+		normalized.Lectures[i] = cl
+	}
+	return &normalized
+}
+
+// DueTime returns when the content block is due. ok is false when the block
+// has no cli_due_at.
+func (r *TimeResolver) DueTime(cb ContentBlockYaml) (t time.Time, ok bool, err error) {
+	if cb.DueAt == "" {
+		return time.Time{}, false, nil
+	}
+	t, err = r.Parse(cb.DueAt)
+	if err != nil {
+		name := cb.Title
+		if name == "" {
+			name = cb.Type + " block"
+		}
+		return time.Time{}, false, fmt.Errorf("cli_due_at of %s: %w", name, err)
+	}
+	return t, true, nil
+}
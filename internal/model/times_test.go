@@ -0,0 +1,118 @@
+package model
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResolve(t *testing.T) {
+	course := CourseYaml{Timezone: "America/New_York", StartDate: "2024-09-02", EndDate: "2024-12-13"}
+	ny, err := course.Location()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a Thursday
+	now := time.Date(2024, 9, 5, 15, 0, 0, 0, ny)
+	r, err := course.TimeResolver(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		in       string
+		want     time.Time
+		relative bool
+	}{
+		{"2024-09-03T09:00:00Z", time.Date(2024, 9, 3, 9, 0, 0, 0, time.UTC), false},
+		{"2024-09-03 09:00", time.Date(2024, 9, 3, 9, 0, 0, 0, ny), false},
+		{"2024-09-03 9am", time.Date(2024, 9, 3, 9, 0, 0, 0, ny), false},
+		{"start", time.Date(2024, 9, 2, 0, 0, 0, 0, ny), false},
+		{"start+2w", time.Date(2024, 9, 16, 0, 0, 0, 0, ny), false},
+		{"start+1w7d", time.Date(2024, 9, 16, 0, 0, 0, 0, ny), false},
+		{"start + 1w 2d 9:30am", time.Date(2024, 9, 11, 9, 30, 0, 0, ny), false},
+		{"end-3d at 5pm", time.Date(2024, 12, 10, 17, 0, 0, 0, ny), false},
+		{"tomorrow noon", time.Date(2024, 9, 6, 12, 0, 0, 0, ny), true},
+		{"thu 9am", time.Date(2024, 9, 5, 9, 0, 0, 0, ny), true},
+		{"next thu 9am", time.Date(2024, 9, 12, 9, 0, 0, 0, ny), true},
+		{"Next Tue 14:00", time.Date(2024, 9, 10, 14, 0, 0, 0, ny), true},
+		{"now+2h", now.Add(2 * time.Hour), true},
+	}
+	for _, c := range cases {
+		res, err := r.Resolve(c.in)
+		if err != nil {
+			t.Errorf("Resolve(%q): %v", c.in, err)
+			continue
+		}
+		if !res.Time.Equal(c.want) || res.RelativeToNow != c.relative {
+			t.Errorf("Resolve(%q) = %s (relative %v), want %s (relative %v)", c.in, res.Time, res.RelativeToNow, c.want, c.relative)
+		}
+	}
+
+	for _, in := range []string{"soon", "start+2x", "tue 9", "tue 13pm", "next"} {
+		if _, err := r.Resolve(in); err == nil {
+			t.Errorf("Resolve(%q): expected an error", in)
+		}
+	}
+
+	// clocks in New York skip 02:00 to 03:00 on 2024-03-10 and repeat 01:00
+	// to 02:00 on 2024-11-03
+	for _, in := range []string{"2024-03-10 02:30", "2024-11-03 1:30am"} {
+		res, err := r.Resolve(in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Note == "" {
+			t.Errorf("Resolve(%q): expected a daylight saving note", in)
+		}
+	}
+}
+
+func TestNormalizeTimes(t *testing.T) {
+	course := CourseYaml{
+		Timezone:  "Europe/Paris",
+		StartDate: "2024-09-02",
+		Lectures: []CourseLectureYaml{
+			{Directory: "a", AvailableAt: "start+1w 10:00"},
+			{Directory: "b"},
+		},
+	}
+	normalized := course.NormalizeTimes(time.Now())
+	if got := normalized.Lectures[0].AvailableAt; got != "2024-09-09T10:00:00+02:00" {
+		t.Errorf("normalized available_at = %q", got)
+	}
+	if normalized.Lectures[1].AvailableAt != "" || course.Lectures[0].AvailableAt != "start+1w 10:00" {
+		t.Error("NormalizeTimes changed more than the available_at times of its copy")
+	}
+
+	r, err := course.TimeResolver(time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	due, ok, err := r.DueTime(ContentBlockYaml{Type: "quiz", DueAt: "start+1w 23:59"})
+	if err != nil || !ok || !due.Equal(time.Date(2024, 9, 9, 21, 59, 0, 0, time.UTC)) {
+		t.Errorf("DueTime = %v, %v, %v", due, ok, err)
+	}
+	if _, ok, _ := r.DueTime(ContentBlockYaml{Type: "markdown"}); ok {
+		t.Error("DueTime reported a due date for a block without cli_due_at")
+	}
+	if _, _, err := r.DueTime(ContentBlockYaml{Type: "quiz", DueAt: "soonish"}); err == nil || !strings.Contains(err.Error(), "cli_due_at of quiz block") {
+		t.Errorf("expected a cli_due_at error, got %v", err)
+	}
+
+	// push never failed on term dates, they are left for check to report
+	course.StartDate = "first monday of september"
+	course.Lectures = append(course.Lectures, CourseLectureYaml{Directory: "c", AvailableAt: "2024-09-16 10:00"})
+	normalized = course.NormalizeTimes(time.Now())
+	if got := normalized.Lectures[0].AvailableAt; got != "start+1w 10:00" {
+		t.Errorf("available_at counted from an unreadable start_date = %q, want it as written", got)
+	}
+	if got := normalized.Lectures[2].AvailableAt; got != "2024-09-16T10:00:00+02:00" {
+		t.Errorf("normalized available_at = %q", got)
+	}
+
+	course.Timezone = "Mars/Olympus_Mons"
+	if got := course.NormalizeTimes(time.Now()).Lectures[2].AvailableAt; got != "2024-09-16 10:00" {
+		t.Errorf("available_at with an unknown timezone = %q, want it as written", got)
+	}
+}
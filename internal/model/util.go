@@ -1,18 +1,172 @@
 package model
 
-import "strings"
+import (
+	"sort"
+	"strings"
+	"unicode"
 
+	"golang.org/x/text/unicode/norm"
+)
+
+// Slugify turns a title into a directory name using the default slug rules
+// with the word separator sep
 func Slugify(x string, sep string) string {
-	// slugify a string. This will convert to lowercase and replace all spaces with hyphens
+	return SlugRules{Separator: sep}.Slugify(x)
+}
 
-	// convert to lowercase
+// legacySlugify is how directory names were made before the slug rules: it
+// only lowercases, drops colons and parentheses and replaces spaces with sep
+func legacySlugify(x string, sep string) string {
 	x = strings.ToLower(x)
-	// replace colon with nothing
 	x = strings.ReplaceAll(x, ":", "")
 	x = strings.ReplaceAll(x, "(", "")
 	x = strings.ReplaceAll(x, ")", "")
-	// replace spaces, parens  with hyphens
-	x = strings.ReplaceAll(x, " ", sep)
+	return strings.ReplaceAll(x, " ", sep)
+}
+
+// SlugRules controls how lecture titles become directory names
+type SlugRules struct {
+	// Separator goes between words, "-" when empty
+	Separator string
+
+	// MaxLength, when positive, limits the length of the name in characters.
+	// Longer names are cut at the end of a word when there is one.
+	MaxLength int
+
+	// KeepUnicode keeps letters outside of ASCII instead of transliterating
+	// them, so a title in Greek or Japanese keeps its words
+	KeepUnicode bool
+
+	// Replacements are applied to the title before anything else, e.g.
+	// {"&": "and", "C++": "cpp"}
+	Replacements map[string]string
+}
+
+// SlugRules returns the rules for lecture directory names set in
+// _course.yml
+func (c CourseYaml) SlugRules() SlugRules {
+	return SlugRules{
+		Separator:    c.Sep(),
+		MaxLength:    c.CLIDirectoryMaxLength,
+		KeepUnicode:  c.CLIDirectoryKeepUnicode,
+		Replacements: c.CLIDirectoryReplacements,
+	}
+}
+
+// transliterations spells letters that don't decompose into an ASCII letter
+// and accents
+var transliterations = map[rune]string{
+	'ß': "ss", 'æ': "ae", 'œ': "oe", 'ø': "o", 'đ': "d", 'ð': "d",
+	'þ': "th", 'ł': "l", 'ı': "i", 'ħ': "h", 'ŀ': "l", 'ŋ': "n",
+}
+
+// Slugify turns a title into a directory name. The title is lowercased,
+// accented letters are transliterated to ASCII (unless KeepUnicode),
+// apostrophes and quotes are dropped and every other run of spaces,
+// punctuation or symbols becomes a single separator. A lone hyphen or
+// underscore between two words, as in a date like 2024-04-18, is kept.
+func (r SlugRules) Slugify(title string) string {
+	sep := r.Separator
+	if sep == "" {
+		sep = "-"
+	}
+
+	keys := make([]string, 0, len(r.Replacements))
+	for k := range r.Replacements {
+		if k != "" {
+			keys = append(keys, k)
+		}
+	}
+	// longest first so "C++" wins over "+"
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) > len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+	for _, k := range keys {
+		title = strings.ReplaceAll(title, k, " "+r.Replacements[k]+" ")
+	}
+
+	title = strings.ToLower(title)
+	if r.KeepUnicode {
+		title = norm.NFC.String(title)
+	} else {
+		title = transliterate(title)
+	}
+
+	keep := func(c rune) bool {
+		if c < unicode.MaxASCII {
+			return ('a' <= c && c <= 'z') || ('0' <= c && c <= '9')
+		}
+		return r.KeepUnicode && (unicode.IsLetter(c) || unicode.IsDigit(c) || unicode.Is(unicode.M, c))
+	}
+
+	// runs of breaking characters become a separator, unless the run is a
+	// single - or _ between two words
+	var b strings.Builder
+	var run []rune
+	flush := func() {
+		if len(run) == 0 {
+			return
+		}
+		if b.Len() > 0 {
+			if len(run) == 1 && (run[0] == '-' || run[0] == '_') {
+				b.WriteRune(run[0])
+			} else {
+				b.WriteString(sep)
+			}
+		}
+		run = run[:0]
+	}
+	for _, c := range title {
+		switch {
+		case keep(c):
+			flush()
+			b.WriteRune(c)
+		case strings.ContainsRune("'’‘`\"“”´", c):
+			// dropped so "don't" becomes dont
+		default:
+			run = append(run, c)
+		}
+	}
+	slug := b.String()
+
+	if r.MaxLength > 0 {
+		slug = truncateSlug(slug, sep, r.MaxLength)
+	}
+	return slug
+}
+
+// transliterate spells title with ASCII letters where it can, removing
+// accents and other marks
+func transliterate(title string) string {
+	var b strings.Builder
+	for _, c := range norm.NFD.String(title) {
+		if unicode.Is(unicode.Mn, c) {
+			continue
+		}
+		if t, ok := transliterations[c]; ok {
+			b.WriteString(t)
+			continue
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
 
-	return x
+// truncateSlug cuts slug to at most max characters, at the end of a word when
+// one ends in the second half of the allowed length
+func truncateSlug(slug, sep string, max int) string {
+	runes := []rune(slug)
+	if len(runes) <= max {
+		return slug
+	}
+	cut := string(runes[:max])
+	if rest := string(runes[max:]); !strings.HasPrefix(rest, sep) {
+		if i := strings.LastIndex(cut, sep); i > 0 && len([]rune(cut[:i])) >= max/2 {
+			cut = cut[:i]
+		}
+	}
+	return strings.TrimRight(cut, sep+"-_")
 }
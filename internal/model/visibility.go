@@ -0,0 +1,114 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// timeLayouts are the formats accepted by ParseTime, most specific first
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// ParseTime parses an RFC3339 timestamp or a date with an optional time of
+// day ("2024-03-28", "2024-03-28 09:00"). Values without a UTC offset are
+// taken to be in loc.
+func ParseTime(s string, loc *time.Location) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q, use RFC3339 (2024-03-28T18:05:41Z) or a date and time (2024-03-28 18:05)", s)
+}
+
+// AvailableTime returns when the lecture becomes visible. ok is false when
+// the lecture has no available_at and so is always visible.
+func (r *TimeResolver) AvailableTime(cl CourseLectureYaml) (t time.Time, ok bool, err error) {
+	if cl.AvailableAt == "" {
+		return time.Time{}, false, nil
+	}
+	t, err = r.Parse(cl.AvailableAt)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("available_at of %s: %w", cl.Directory, err)
+	}
+	return t, true, nil
+}
+
+// AvailableTime returns when the lecture becomes visible, resolving relative
+// times from now. ok is false when the lecture has no available_at.
+func (c CourseYaml) AvailableTime(cl CourseLectureYaml) (t time.Time, ok bool, err error) {
+	r, err := c.TimeResolver(time.Now())
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return r.AvailableTime(cl)
+}
+
+// VisibleAt reports whether students can see the lecture at t
+func (r *TimeResolver) VisibleAt(cl CourseLectureYaml, t time.Time) (bool, error) {
+	available, ok, err := r.AvailableTime(cl)
+	if err != nil || !ok {
+		return err == nil, err
+	}
+	return !available.After(t), nil
+}
+
+// VisibleLectures returns the lectures students can see at t, in course order
+func (c CourseYaml) VisibleLectures(t time.Time) ([]CourseLectureYaml, error) {
+	r, err := c.TimeResolver(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	var visible []CourseLectureYaml
+	for _, cl := range c.Lectures {
+		ok, err := r.VisibleAt(cl, t)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			visible = append(visible, cl)
+		}
+	}
+	return visible, nil
+}
+
+// Release is the moment a lecture becomes visible
+type Release struct {
+	Lecture CourseLectureYaml
+	At      time.Time
+}
+
+// Timeline returns the lectures with an available_at, ordered by release
+// time. When the course has a start or end date, releases outside of the
+// term are left out.
+func (c CourseYaml) Timeline() ([]Release, error) {
+	r, err := c.TimeResolver(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	start, end, err := c.termBounds(r.Location)
+	if err != nil {
+		return nil, err
+	}
+
+	var releases []Release
+	for _, cl := range c.Lectures {
+		at, ok, err := r.AvailableTime(cl)
+		if err != nil {
+			return nil, err
+		}
+		if !ok || (!start.IsZero() && at.Before(start)) || (!end.IsZero() && at.After(end)) {
+			continue
+		}
+		releases = append(releases, Release{Lecture: cl, At: at})
+	}
+	sort.SliceStable(releases, func(i, j int) bool { return releases[i].At.Before(releases[j].At) })
+	return releases, nil
+}
@@ -0,0 +1,67 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTime(t *testing.T) {
+	loc := time.FixedZone("test", -5*60*60)
+	cases := map[string]time.Time{
+		"2024-09-02T08:00:00Z":      time.Date(2024, 9, 2, 8, 0, 0, 0, time.UTC),
+		"2024-09-02T08:00:00+02:00": time.Date(2024, 9, 2, 6, 0, 0, 0, time.UTC),
+		"2024-09-02 08:00":          time.Date(2024, 9, 2, 8, 0, 0, 0, loc),
+		"2024-09-02":                time.Date(2024, 9, 2, 0, 0, 0, 0, loc),
+	}
+	for in, want := range cases {
+		got, err := ParseTime(in, loc)
+		if err != nil {
+			t.Errorf("ParseTime(%q): %v", in, err)
+			continue
+		}
+		if !got.Equal(want) {
+			t.Errorf("ParseTime(%q) = %s, want %s", in, got, want)
+		}
+	}
+	if _, err := ParseTime("next monday", loc); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestVisibility(t *testing.T) {
+	course := CourseYaml{
+		StartDate: "2024-09-01",
+		EndDate:   "2024-12-20",
+		Lectures: []CourseLectureYaml{
+			{Directory: "welcome"},
+			{Directory: "week-2", AvailableAt: "2024-09-09T08:00:00Z"},
+			{Directory: "week-1", AvailableAt: "2024-09-02T08:00:00Z"},
+			{Directory: "bonus", AvailableAt: "2025-01-10T08:00:00Z"},
+		},
+	}
+
+	visible, err := course.VisibleLectures(time.Date(2024, 9, 2, 8, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dirs []string
+	for _, cl := range visible {
+		dirs = append(dirs, cl.Directory)
+	}
+	if len(dirs) != 2 || dirs[0] != "welcome" || dirs[1] != "week-1" {
+		t.Errorf("unexpected visible lectures %v", dirs)
+	}
+
+	releases, err := course.Timeline()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(releases) != 2 || releases[0].Lecture.Directory != "week-1" || releases[1].Lecture.Directory != "week-2" {
+		t.Errorf("unexpected timeline %v", releases)
+	}
+
+	course.Lectures[1].AvailableAt = "soon"
+	if _, err := course.VisibleLectures(time.Now()); err == nil {
+		t.Error("expected an error for an invalid available_at")
+	}
+}
@@ -0,0 +1,75 @@
+package model
+
+import (
+	"os"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// yamlPositions gives access to the source line numbers of a YAML document so
+// diagnostics can point at the offending entry. The typed structs are still
+// parsed with ParseCourseYaml/ParseLectureYaml; this is only used for lookups.
+type yamlPositions struct {
+	root *yamlv3.Node
+}
+
+func loadYamlPositions(path string) *yamlPositions {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return &yamlPositions{}
+	}
+	var doc yamlv3.Node
+	if err := yamlv3.Unmarshal(data, &doc); err != nil {
+		return &yamlPositions{}
+	}
+	if doc.Kind == yamlv3.DocumentNode && len(doc.Content) > 0 {
+		return &yamlPositions{root: doc.Content[0]}
+	}
+	return &yamlPositions{root: &doc}
+}
+
+// Line walks the document following mapping keys (string) and sequence indices
+// (int) and returns the line of the deepest node found. Missing entries fall
+// back to the closest parent so the caller always gets something useful; 0 is
+// returned only when the document itself could not be parsed.
+func (p *yamlPositions) Line(path ...interface{}) int {
+	if p == nil || p.root == nil {
+		return 0
+	}
+	node := p.root
+	line := node.Line
+	for _, step := range path {
+		next, keyLine := child(node, step)
+		if next == nil {
+			return line
+		}
+		node = next
+		line = next.Line
+		if keyLine > 0 {
+			line = keyLine
+		}
+	}
+	return line
+}
+
+// child returns the node found at step along with the line of its key when
+// step refers to a mapping entry.
+func child(node *yamlv3.Node, step interface{}) (*yamlv3.Node, int) {
+	switch s := step.(type) {
+	case string:
+		if node.Kind != yamlv3.MappingNode {
+			return nil, 0
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == s {
+				return node.Content[i+1], node.Content[i].Line
+			}
+		}
+	case int:
+		if node.Kind != yamlv3.SequenceNode || s < 0 || s >= len(node.Content) {
+			return nil, 0
+		}
+		return node.Content[s], 0
+	}
+	return nil, 0
+}
@@ -0,0 +1,232 @@
+package moodle
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/sglyon/jupyteach/internal/model"
+)
+
+var (
+	giftTitle    = regexp.MustCompile(`(?s)^::(.*?)::`)
+	giftFormat   = regexp.MustCompile(`^\[(html|markdown|plain|moodle)\]`)
+	giftWeight   = regexp.MustCompile(`^%(-?[0-9.]+)%`)
+	giftCategory = regexp.MustCompile(`^\$CATEGORY:\s*(.*)$`)
+)
+
+// giftUnescape removes the backslash from the characters GIFT reserves
+func giftUnescape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && strings.ContainsRune(`~=#{}:\`, rune(s[i+1])) {
+			i++
+			b.WriteByte(s[i])
+			continue
+		}
+		if s[i] == '\\' && i+1 < len(s) && s[i+1] == 'n' {
+			i++
+			b.WriteByte('\n')
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// indexUnescaped returns the index of the first c in s not preceded by a
+// backslash, or -1
+func indexUnescaped(s string, c byte) int {
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' {
+			i++
+			continue
+		}
+		if s[i] == c {
+			return i
+		}
+	}
+	return -1
+}
+
+// splitGiftAnswers splits the inside of a GIFT answer block into answers,
+// each starting with = (correct) or ~ (wrong, or weighted)
+func splitGiftAnswers(s string) []string {
+	var parts []string
+	start := -1
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' {
+			i++
+			continue
+		}
+		if s[i] == '=' || s[i] == '~' {
+			if start >= 0 {
+				parts = append(parts, s[start:i])
+			}
+			start = i
+		}
+	}
+	if start >= 0 {
+		parts = append(parts, s[start:])
+	}
+	return parts
+}
+
+func parseGiftAnswer(part string) (answer, error) {
+	correct := part[0] == '='
+	text := strings.TrimSpace(part[1:])
+	fraction := 0.0
+	if correct {
+		fraction = 100
+	}
+	if m := giftWeight.FindStringSubmatch(text); m != nil {
+		w, err := strconv.ParseFloat(m[1], 64)
+		if err != nil {
+			return answer{}, fmt.Errorf("invalid weight %q", m[0])
+		}
+		fraction = w
+		text = strings.TrimSpace(text[len(m[0]):])
+	}
+	// drop the feedback
+	if i := indexUnescaped(text, '#'); i >= 0 {
+		text = text[:i]
+	}
+	return answer{Text: giftUnescape(text), Fraction: fraction}, nil
+}
+
+// splitGiftQuestions splits a GIFT file into question blocks (separated by
+// blank lines) after removing comments. Category commands are returned as
+// blocks of their own.
+func splitGiftQuestions(data string) []string {
+	var blocks []string
+	var current []string
+	flush := func() {
+		if len(current) > 0 {
+			blocks = append(blocks, strings.Join(current, "\n"))
+			current = nil
+		}
+	}
+	for _, line := range strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "//"):
+			continue
+		case trimmed == "":
+			flush()
+		case giftCategory.MatchString(trimmed):
+			flush()
+			blocks = append(blocks, trimmed)
+		default:
+			current = append(current, line)
+		}
+	}
+	flush()
+	return blocks
+}
+
+// ParseGIFT converts the questions of a GIFT file. Categories become question
+// topics.
+func ParseGIFT(data []byte) Result {
+	var result Result
+	var category string
+
+	// n counts questions only, so names match the numbering in the file
+	n := 0
+	for _, block := range splitGiftQuestions(string(data)) {
+		if m := giftCategory.FindStringSubmatch(block); m != nil {
+			parts := strings.Split(strings.TrimSpace(m[1]), "/")
+			category = strings.TrimSpace(parts[len(parts)-1])
+			continue
+		}
+
+		n++
+		name := fmt.Sprintf("question %d", n)
+		if m := giftTitle.FindStringSubmatch(block); m != nil {
+			name = giftUnescape(m[1])
+			block = block[len(m[0]):]
+		}
+		block = strings.TrimSpace(block)
+		block = giftFormat.ReplaceAllString(block, "")
+
+		open := indexUnescaped(block, '{')
+		if open < 0 {
+			result.skip(name, "description only, no answers")
+			continue
+		}
+		closeAt := indexUnescaped(block[open:], '}')
+		if closeAt < 0 {
+			result.skip(name, "answer block is not closed")
+			continue
+		}
+		closeAt += open
+		inner := strings.TrimSpace(block[open+1 : closeAt])
+		before := strings.TrimSpace(block[:open])
+		after := strings.TrimSpace(block[closeAt+1:])
+
+		text := giftUnescape(before)
+		if after != "" {
+			// missing word format, the answers fill the gap in the text
+			text = giftUnescape(before + " _____ " + after)
+		}
+
+		q, err := giftQuestion(text, inner)
+		if err != nil {
+			result.skip(name, "%s", err)
+			continue
+		}
+		if category != "" {
+			q.Topics = []string{category}
+		}
+		result.Questions = append(result.Questions, q)
+	}
+	return result
+}
+
+func giftQuestion(text, inner string) (model.Question, error) {
+	switch {
+	case inner == "":
+		return model.Question{QuestionType: "freeform", QuestionText: text, Points: 1}, nil
+	case strings.HasPrefix(inner, "#"):
+		return model.Question{}, fmt.Errorf("numerical questions are not supported")
+	}
+
+	if tf := strings.ToUpper(strings.TrimSpace(strings.SplitN(inner, "#", 2)[0])); tf == "T" || tf == "TRUE" || tf == "F" || tf == "FALSE" {
+		solution := "True"
+		if strings.HasPrefix(tf, "F") {
+			solution = "False"
+		}
+		return model.Question{
+			QuestionType: "single_selection",
+			QuestionText: text,
+			Points:       1,
+			Options:      []string{"True", "False"},
+			Solution:     solution,
+		}, nil
+	}
+
+	var answers []answer
+	hasWrong, weighted := false, false
+	for _, part := range splitGiftAnswers(inner) {
+		if strings.Contains(part, "->") {
+			return model.Question{}, fmt.Errorf("matching questions are not supported")
+		}
+		a, err := parseGiftAnswer(part)
+		if err != nil {
+			return model.Question{}, err
+		}
+		if part[0] == '~' {
+			hasWrong = true
+			weighted = weighted || a.Fraction > 0
+		}
+		answers = append(answers, a)
+	}
+	if len(answers) == 0 {
+		return model.Question{}, fmt.Errorf("no answers found")
+	}
+
+	if !hasWrong {
+		return shortAnswerQuestion(text, 1, answers)
+	}
+	return choiceQuestion(text, 1, answers, weighted)
+}
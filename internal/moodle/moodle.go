@@ -0,0 +1,95 @@
+// Package moodle converts Moodle question banks, in either the GIFT or the
+// Moodle XML format, into jupyteach quiz questions
+package moodle
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/sglyon/jupyteach/internal/model"
+)
+
+// Skipped is a question that could not be converted
+type Skipped struct {
+	Name   string
+	Reason string
+}
+
+func (s Skipped) String() string {
+	return fmt.Sprintf("%s: %s", s.Name, s.Reason)
+}
+
+// Result holds the converted questions along with those that were skipped
+type Result struct {
+	Questions []model.Question
+	Skipped   []Skipped
+}
+
+func (r *Result) skip(name, format string, args ...interface{}) {
+	r.Skipped = append(r.Skipped, Skipped{Name: name, Reason: fmt.Sprintf(format, args...)})
+}
+
+// answer is a single answer of a Moodle question. Fraction is the share of
+// the grade it is worth, from -100 to 100.
+type answer struct {
+	Text     string
+	Fraction float64
+}
+
+// choiceQuestion builds a selection question from Moodle answers. A single
+// correct answer makes a single selection question unless multiple is set.
+func choiceQuestion(text string, points int, answers []answer, multiple bool) (model.Question, error) {
+	q := model.Question{QuestionText: text, Points: points}
+	var correct []string
+	for _, a := range answers {
+		q.Options = append(q.Options, a.Text)
+		if a.Fraction > 0 {
+			correct = append(correct, a.Text)
+		}
+	}
+	if len(correct) == 0 {
+		return q, fmt.Errorf("no correct answer")
+	}
+
+	if multiple || len(correct) > 1 {
+		q.QuestionType = "multiple_selection"
+		q.Solution = model.FormatMultipleSelectionSolution(correct)
+	} else {
+		q.QuestionType = "single_selection"
+		q.Solution = correct[0]
+	}
+	return q, nil
+}
+
+// shortAnswerQuestion builds a freeform question whose solution is the first
+// fully correct answer
+func shortAnswerQuestion(text string, points int, answers []answer) (model.Question, error) {
+	for _, a := range answers {
+		if a.Fraction >= 100 {
+			return model.Question{QuestionType: "freeform", QuestionText: text, Points: points, Solution: a.Text}, nil
+		}
+	}
+	return model.Question{}, fmt.Errorf("no fully correct answer")
+}
+
+func gradeToPoints(grade float64) int {
+	points := int(math.Round(grade))
+	if points < 1 {
+		return 1
+	}
+	return points
+}
+
+var outerParagraph = regexp.MustCompile(`(?s)^\s*<p[^>]*>(.*?)</p>\s*$`)
+
+// plainText removes a single paragraph wrapping an html answer, which is how
+// the Moodle editor stores short answers
+func plainText(s string) string {
+	s = strings.TrimSpace(s)
+	if m := outerParagraph.FindStringSubmatch(s); m != nil && !strings.Contains(m[1], "<p") {
+		return strings.TrimSpace(m[1])
+	}
+	return s
+}
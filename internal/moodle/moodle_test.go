@@ -0,0 +1,107 @@
+package moodle
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sglyon/jupyteach/internal/model"
+)
+
+const giftSample = `// a comment
+$CATEGORY: $course$/Week 1/Arithmetic
+
+::Q1:: What is 2 + 2? {
+	~3#Too small
+	=4#Right
+	~5
+}
+
+::Q2:: Which are even? {~%50%2 ~%50%4 ~%-100%3}
+
+::Q3:: The capital of France is {=Paris =paris}.
+
+::Q4:: [markdown]Explain **why**. {}
+
+::Q5:: The sun rises in the east. {T}
+
+::Q6:: Match them {=a -> 1 =b -> 2}
+
+::Q7:: Escaped \{braces\} {=yes ~no}
+`
+
+func TestParseGIFT(t *testing.T) {
+	result := ParseGIFT([]byte(giftSample))
+
+	expected := []model.Question{
+		{QuestionType: "single_selection", QuestionText: "What is 2 + 2?", Points: 1, Options: []string{"3", "4", "5"}, Solution: "4", Topics: []string{"Arithmetic"}},
+		{QuestionType: "multiple_selection", QuestionText: "Which are even?", Points: 1, Options: []string{"2", "4", "3"}, Solution: `["2", "4"]`, Topics: []string{"Arithmetic"}},
+		{QuestionType: "freeform", QuestionText: "The capital of France is _____ .", Points: 1, Solution: "Paris", Topics: []string{"Arithmetic"}},
+		{QuestionType: "freeform", QuestionText: "Explain **why**.", Points: 1, Topics: []string{"Arithmetic"}},
+		{QuestionType: "single_selection", QuestionText: "The sun rises in the east.", Points: 1, Options: []string{"True", "False"}, Solution: "True", Topics: []string{"Arithmetic"}},
+		{QuestionType: "single_selection", QuestionText: "Escaped {braces}", Points: 1, Options: []string{"yes", "no"}, Solution: "yes", Topics: []string{"Arithmetic"}},
+	}
+	if !reflect.DeepEqual(result.Questions, expected) {
+		t.Errorf("unexpected questions:\ngot  %+v\nwant %+v", result.Questions, expected)
+	}
+	if len(result.Skipped) != 1 || result.Skipped[0].Name != "Q6" {
+		t.Errorf("expected Q6 to be skipped, got %v", result.Skipped)
+	}
+}
+
+func TestParseGIFTNames(t *testing.T) {
+	gift := "$CATEGORY: a\n\nFirst {}\n\n$CATEGORY: b\n\nSecond {#1:0}\n"
+	result := ParseGIFT([]byte(gift))
+	if len(result.Skipped) != 1 || result.Skipped[0].Name != "question 2" {
+		t.Errorf("expected question 2 to be skipped, got %v", result.Skipped)
+	}
+}
+
+const xmlSample = `<?xml version="1.0" encoding="UTF-8"?>
+<quiz>
+  <question type="category">
+    <category><text>$course$/top/Loops</text></category>
+  </question>
+  <question type="multichoice">
+    <name><text>Pick</text></name>
+    <questiontext format="html"><text><![CDATA[<p>Which are loops?</p>]]></text></questiontext>
+    <defaultgrade>2.0000000</defaultgrade>
+    <single>false</single>
+    <answer fraction="50" format="html"><text><![CDATA[<p>for</p>]]></text></answer>
+    <answer fraction="50" format="html"><text>while</text></answer>
+    <answer fraction="0" format="html"><text>if</text></answer>
+  </question>
+  <question type="shortanswer">
+    <name><text>Keyword</text></name>
+    <questiontext format="html"><text>Keyword to leave a loop?</text></questiontext>
+    <defaultgrade>1</defaultgrade>
+    <answer fraction="100"><text>break</text></answer>
+  </question>
+  <question type="essay">
+    <name><text>Essay</text></name>
+    <questiontext format="html"><text>Discuss.</text></questiontext>
+    <tags><tag><text>writing</text></tag></tags>
+  </question>
+  <question type="numerical">
+    <name><text>Number</text></name>
+    <questiontext format="html"><text>1+1?</text></questiontext>
+  </question>
+</quiz>`
+
+func TestParseMoodleXML(t *testing.T) {
+	result, err := ParseMoodleXML([]byte(xmlSample))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []model.Question{
+		{QuestionType: "multiple_selection", QuestionText: "<p>Which are loops?</p>", Points: 2, Options: []string{"for", "while", "if"}, Solution: `["for", "while"]`, Topics: []string{"Loops"}},
+		{QuestionType: "freeform", QuestionText: "Keyword to leave a loop?", Points: 1, Solution: "break", Topics: []string{"Loops"}},
+		{QuestionType: "freeform", QuestionText: "Discuss.", Points: 1, Topics: []string{"Loops", "writing"}},
+	}
+	if !reflect.DeepEqual(result.Questions, expected) {
+		t.Errorf("unexpected questions:\ngot  %+v\nwant %+v", result.Questions, expected)
+	}
+	if len(result.Skipped) != 1 || result.Skipped[0].Name != "Number" {
+		t.Errorf("expected Number to be skipped, got %v", result.Skipped)
+	}
+}
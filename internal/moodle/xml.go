@@ -0,0 +1,103 @@
+package moodle
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/sglyon/jupyteach/internal/model"
+)
+
+type xmlText struct {
+	Text string `xml:"text"`
+}
+
+type xmlAnswer struct {
+	Fraction float64 `xml:"fraction,attr"`
+	Text     string  `xml:"text"`
+}
+
+type xmlQuestion struct {
+	Type         string      `xml:"type,attr"`
+	Name         xmlText     `xml:"name"`
+	QuestionText xmlText     `xml:"questiontext"`
+	DefaultGrade float64     `xml:"defaultgrade"`
+	Single       string      `xml:"single"`
+	Answers      []xmlAnswer `xml:"answer"`
+	Category     xmlText     `xml:"category"`
+	Tags         []xmlText   `xml:"tags>tag"`
+}
+
+type xmlQuiz struct {
+	Questions []xmlQuestion `xml:"question"`
+}
+
+// ParseMoodleXML converts the questions of a Moodle XML export. Categories
+// and tags become question topics.
+func ParseMoodleXML(data []byte) (Result, error) {
+	var result Result
+	var quiz xmlQuiz
+	if err := xml.Unmarshal(data, &quiz); err != nil {
+		return result, fmt.Errorf("invalid Moodle XML: %w", err)
+	}
+
+	var category string
+	// n counts questions only, categories are entries of the file too
+	n := 0
+	for _, xq := range quiz.Questions {
+		if xq.Type != "category" {
+			n++
+		}
+		name := strings.TrimSpace(xq.Name.Text)
+		if name == "" {
+			name = fmt.Sprintf("question %d", n)
+		}
+
+		var q model.Question
+		var err error
+		text := strings.TrimSpace(xq.QuestionText.Text)
+		points := 1
+		if xq.DefaultGrade > 0 {
+			points = gradeToPoints(xq.DefaultGrade)
+		}
+		answers := make([]answer, len(xq.Answers))
+		for i, a := range xq.Answers {
+			answers[i] = answer{Text: plainText(a.Text), Fraction: a.Fraction}
+		}
+
+		switch xq.Type {
+		case "category":
+			parts := strings.Split(strings.TrimSpace(xq.Category.Text), "/")
+			category = strings.TrimSpace(parts[len(parts)-1])
+			continue
+		case "description":
+			// not a question, nothing to convert
+			continue
+		case "multichoice":
+			q, err = choiceQuestion(text, points, answers, xq.Single == "false" || xq.Single == "0")
+		case "truefalse":
+			q, err = choiceQuestion(text, points, answers, false)
+		case "shortanswer":
+			q, err = shortAnswerQuestion(text, points, answers)
+		case "essay":
+			q = model.Question{QuestionType: "freeform", QuestionText: text, Points: points}
+		default:
+			err = fmt.Errorf("%s questions are not supported", xq.Type)
+		}
+		if err != nil {
+			result.skip(name, "%s", err)
+			continue
+		}
+
+		if category != "" {
+			q.Topics = append(q.Topics, category)
+		}
+		for _, tag := range xq.Tags {
+			if t := strings.TrimSpace(tag.Text); t != "" {
+				q.Topics = append(q.Topics, t)
+			}
+		}
+		result.Questions = append(result.Questions, q)
+	}
+	return result, nil
+}
@@ -0,0 +1,67 @@
+package notebook
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Parse decodes an nbformat v4 notebook
+func Parse(data []byte) (*Notebook, error) {
+	var nb Notebook
+	if err := json.Unmarshal(data, &nb); err != nil {
+		return nil, err
+	}
+	if nb.NBFormat != 4 {
+		return nil, fmt.Errorf("unsupported nbformat %d, only version 4 notebooks are supported", nb.NBFormat)
+	}
+	return &nb, nil
+}
+
+// Read decodes a notebook from r
+func Read(r io.Reader) (*Notebook, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// ReadFile decodes the notebook stored at path
+func ReadFile(path string) (*Notebook, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	nb, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return nb, nil
+}
+
+// Marshal encodes the notebook the same way Jupyter does: sorted keys, one
+// space of indentation and a trailing newline
+func Marshal(nb *Notebook) ([]byte, error) {
+	raw, err := marshal(nb)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, raw, "", " "); err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+// WriteFile encodes the notebook to path
+func WriteFile(path string, nb *Notebook) error {
+	data, err := Marshal(nb)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
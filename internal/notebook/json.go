@@ -0,0 +1,62 @@
+package notebook
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// fields holds the raw members of a JSON object. Known members are moved into
+// typed struct fields with take; whatever is left over is kept so it can be
+// written back unchanged.
+type fields map[string]json.RawMessage
+
+// marshal encodes v without escaping <, > and &, matching what Jupyter writes
+func marshal(v interface{}) (json.RawMessage, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
+
+func decodeFields(data []byte) (fields, error) {
+	var f fields
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, err
+	}
+	if f == nil {
+		f = fields{}
+	}
+	return f, nil
+}
+
+// take unmarshals the member named key into v and removes it from f. It
+// reports whether the member was present.
+func (f fields) take(key string, v interface{}) (bool, error) {
+	raw, ok := f[key]
+	if !ok {
+		return false, nil
+	}
+	delete(f, key)
+	return true, json.Unmarshal(raw, v)
+}
+
+func (f fields) put(key string, v interface{}) error {
+	raw, err := marshal(v)
+	if err != nil {
+		return err
+	}
+	f[key] = raw
+	return nil
+}
+
+// clone returns a copy of f that can be extended without modifying f
+func (f fields) clone() fields {
+	out := make(fields, len(f))
+	for k, v := range f {
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,490 @@
+// Package notebook reads and writes Jupyter notebooks in the nbformat v4
+// format. Parsing and writing a notebook round-trips losslessly: members that
+// are not modeled by the Go types are kept and written back unchanged.
+package notebook
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+var (
+	CellTypes   = [...]string{"code", "markdown", "raw"}
+	OutputTypes = [...]string{"stream", "display_data", "execute_result", "error"}
+)
+
+// MultilineString is a string that nbformat stores either as a single string
+// or as a list of lines. The original form is remembered so it can be written
+// back the same way.
+type MultilineString struct {
+	Lines  []string
+	IsList bool
+}
+
+// NewMultilineString splits s into lines the way Jupyter does, keeping the
+// trailing newline on every line but the last
+func NewMultilineString(s string) MultilineString {
+	if s == "" {
+		return MultilineString{Lines: []string{}, IsList: true}
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return MultilineString{Lines: lines, IsList: true}
+}
+
+func (m MultilineString) String() string {
+	return strings.Join(m.Lines, "")
+}
+
+func (m *MultilineString) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*m = MultilineString{Lines: []string{s}}
+		return nil
+	}
+	var lines []string
+	if err := json.Unmarshal(data, &lines); err != nil {
+		return err
+	}
+	if lines == nil {
+		lines = []string{}
+	}
+	*m = MultilineString{Lines: lines, IsList: true}
+	return nil
+}
+
+func (m MultilineString) MarshalJSON() ([]byte, error) {
+	if !m.IsList {
+		return marshal(m.String())
+	}
+	if m.Lines == nil {
+		return []byte("[]"), nil
+	}
+	return marshal(m.Lines)
+}
+
+// Metadata is a free-form metadata object, kept as raw JSON
+type Metadata map[string]json.RawMessage
+
+// MimeBundle maps mime types to their (raw JSON) representation of an output
+type MimeBundle map[string]json.RawMessage
+
+// Text returns the value for mime as a string, joining list values. It returns
+// false if the mime type is missing or not a string (e.g. application/json).
+func (b MimeBundle) Text(mime string) (string, bool) {
+	raw, ok := b[mime]
+	if !ok {
+		return "", false
+	}
+	var m MultilineString
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return "", false
+	}
+	return m.String(), true
+}
+
+type KernelSpec struct {
+	Name        string
+	DisplayName string
+	Language    string
+	extra       fields
+}
+
+func (k *KernelSpec) UnmarshalJSON(data []byte) error {
+	f, err := decodeFields(data)
+	if err != nil {
+		return err
+	}
+	for key, dst := range map[string]*string{"name": &k.Name, "display_name": &k.DisplayName, "language": &k.Language} {
+		if _, err := f.take(key, dst); err != nil {
+			return err
+		}
+	}
+	k.extra = f
+	return nil
+}
+
+func (k KernelSpec) MarshalJSON() ([]byte, error) {
+	f := k.extra.clone()
+	if err := f.put("name", k.Name); err != nil {
+		return nil, err
+	}
+	if err := f.put("display_name", k.DisplayName); err != nil {
+		return nil, err
+	}
+	if k.Language != "" {
+		if err := f.put("language", k.Language); err != nil {
+			return nil, err
+		}
+	}
+	return marshal(f)
+}
+
+type LanguageInfo struct {
+	Name          string
+	Version       string
+	FileExtension string
+	extra         fields
+}
+
+func (l *LanguageInfo) UnmarshalJSON(data []byte) error {
+	f, err := decodeFields(data)
+	if err != nil {
+		return err
+	}
+	for key, dst := range map[string]*string{"name": &l.Name, "version": &l.Version, "file_extension": &l.FileExtension} {
+		if _, err := f.take(key, dst); err != nil {
+			return err
+		}
+	}
+	l.extra = f
+	return nil
+}
+
+func (l LanguageInfo) MarshalJSON() ([]byte, error) {
+	f := l.extra.clone()
+	if err := f.put("name", l.Name); err != nil {
+		return nil, err
+	}
+	for key, v := range map[string]string{"version": l.Version, "file_extension": l.FileExtension} {
+		if v != "" {
+			if err := f.put(key, v); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return marshal(f)
+}
+
+// NotebookMetadata is the top level metadata of a notebook. Members other
+// than the kernelspec, language info and title are kept in Extra.
+type NotebookMetadata struct {
+	KernelSpec   *KernelSpec
+	LanguageInfo *LanguageInfo
+	Title        string
+	Extra        Metadata
+}
+
+func (m *NotebookMetadata) UnmarshalJSON(data []byte) error {
+	f, err := decodeFields(data)
+	if err != nil {
+		return err
+	}
+	if _, err := f.take("kernelspec", &m.KernelSpec); err != nil {
+		return err
+	}
+	if _, err := f.take("language_info", &m.LanguageInfo); err != nil {
+		return err
+	}
+	if _, err := f.take("title", &m.Title); err != nil {
+		return err
+	}
+	m.Extra = Metadata(f)
+	return nil
+}
+
+func (m NotebookMetadata) MarshalJSON() ([]byte, error) {
+	f := fields(m.Extra).clone()
+	if m.KernelSpec != nil {
+		if err := f.put("kernelspec", m.KernelSpec); err != nil {
+			return nil, err
+		}
+	}
+	if m.LanguageInfo != nil {
+		if err := f.put("language_info", m.LanguageInfo); err != nil {
+			return nil, err
+		}
+	}
+	if m.Title != "" {
+		if err := f.put("title", m.Title); err != nil {
+			return nil, err
+		}
+	}
+	return marshal(f)
+}
+
+// Output is a single output of a code cell. Which fields are used depends on
+// OutputType:
+//
+//   - stream: Name and Text
+//   - display_data: Data and Metadata
+//   - execute_result: Data, Metadata and ExecutionCount
+//   - error: EName, EValue and Traceback
+type Output struct {
+	OutputType     string
+	Name           string
+	Text           MultilineString
+	Data           MimeBundle
+	Metadata       Metadata
+	ExecutionCount *int
+	EName          string
+	EValue         string
+	Traceback      []string
+	extra          fields
+}
+
+func (o *Output) UnmarshalJSON(data []byte) error {
+	f, err := decodeFields(data)
+	if err != nil {
+		return err
+	}
+	targets := []struct {
+		key string
+		dst interface{}
+	}{
+		{"output_type", &o.OutputType},
+		{"name", &o.Name},
+		{"text", &o.Text},
+		{"data", &o.Data},
+		{"metadata", &o.Metadata},
+		{"execution_count", &o.ExecutionCount},
+		{"ename", &o.EName},
+		{"evalue", &o.EValue},
+		{"traceback", &o.Traceback},
+	}
+	for _, t := range targets {
+		if _, err := f.take(t.key, t.dst); err != nil {
+			return err
+		}
+	}
+	o.extra = f
+	return nil
+}
+
+func (o Output) MarshalJSON() ([]byte, error) {
+	f := o.extra.clone()
+	var err error
+	put := func(key string, v interface{}) {
+		if err == nil {
+			err = f.put(key, v)
+		}
+	}
+	put("output_type", o.OutputType)
+	switch o.OutputType {
+	case "stream":
+		put("name", o.Name)
+		put("text", o.Text)
+	case "display_data", "execute_result":
+		data, metadata := o.Data, o.Metadata
+		if data == nil {
+			data = MimeBundle{}
+		}
+		if metadata == nil {
+			metadata = Metadata{}
+		}
+		put("data", data)
+		put("metadata", metadata)
+		if o.OutputType == "execute_result" {
+			put("execution_count", o.ExecutionCount)
+		}
+	case "error":
+		traceback := o.Traceback
+		if traceback == nil {
+			traceback = []string{}
+		}
+		put("ename", o.EName)
+		put("evalue", o.EValue)
+		put("traceback", traceback)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return marshal(f)
+}
+
+// Cell is a single notebook cell. Outputs and ExecutionCount are only used
+// for code cells and Attachments only for markdown and raw cells.
+type Cell struct {
+	CellType       string
+	ID             string
+	Metadata       Metadata
+	Source         MultilineString
+	Outputs        []Output
+	ExecutionCount *int
+	Attachments    json.RawMessage
+	extra          fields
+}
+
+func (c *Cell) UnmarshalJSON(data []byte) error {
+	f, err := decodeFields(data)
+	if err != nil {
+		return err
+	}
+	targets := []struct {
+		key string
+		dst interface{}
+	}{
+		{"cell_type", &c.CellType},
+		{"id", &c.ID},
+		{"metadata", &c.Metadata},
+		{"source", &c.Source},
+		{"outputs", &c.Outputs},
+		{"execution_count", &c.ExecutionCount},
+		{"attachments", &c.Attachments},
+	}
+	for _, t := range targets {
+		if _, err := f.take(t.key, t.dst); err != nil {
+			return err
+		}
+	}
+	c.extra = f
+	return nil
+}
+
+func (c Cell) MarshalJSON() ([]byte, error) {
+	f := c.extra.clone()
+	var err error
+	put := func(key string, v interface{}) {
+		if err == nil {
+			err = f.put(key, v)
+		}
+	}
+	metadata := c.Metadata
+	if metadata == nil {
+		metadata = Metadata{}
+	}
+	put("cell_type", c.CellType)
+	if c.ID != "" {
+		put("id", c.ID)
+	}
+	put("metadata", metadata)
+	put("source", c.Source)
+	if c.CellType == "code" {
+		outputs := c.Outputs
+		if outputs == nil {
+			outputs = []Output{}
+		}
+		put("outputs", outputs)
+		put("execution_count", c.ExecutionCount)
+	}
+	if c.Attachments != nil {
+		put("attachments", c.Attachments)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return marshal(f)
+}
+
+// Notebook is a parsed nbformat v4 notebook
+type Notebook struct {
+	Cells         []Cell
+	Metadata      NotebookMetadata
+	NBFormat      int
+	NBFormatMinor int
+	extra         fields
+}
+
+func (nb *Notebook) UnmarshalJSON(data []byte) error {
+	f, err := decodeFields(data)
+	if err != nil {
+		return err
+	}
+	targets := []struct {
+		key string
+		dst interface{}
+	}{
+		{"cells", &nb.Cells},
+		{"metadata", &nb.Metadata},
+		{"nbformat", &nb.NBFormat},
+		{"nbformat_minor", &nb.NBFormatMinor},
+	}
+	for _, t := range targets {
+		if _, err := f.take(t.key, t.dst); err != nil {
+			return err
+		}
+	}
+	nb.extra = f
+	return nil
+}
+
+func (nb Notebook) MarshalJSON() ([]byte, error) {
+	f := nb.extra.clone()
+	var err error
+	put := func(key string, v interface{}) {
+		if err == nil {
+			err = f.put(key, v)
+		}
+	}
+	cells := nb.Cells
+	if cells == nil {
+		cells = []Cell{}
+	}
+	put("cells", cells)
+	put("metadata", nb.Metadata)
+	put("nbformat", nb.NBFormat)
+	put("nbformat_minor", nb.NBFormatMinor)
+	if err != nil {
+		return nil, err
+	}
+	return marshal(f)
+}
+
+// Title returns the notebook title: the `title` metadata if set, otherwise
+// the text of the first markdown heading
+func (nb *Notebook) Title() string {
+	if nb.Metadata.Title != "" {
+		return nb.Metadata.Title
+	}
+	for _, c := range nb.Cells {
+		if c.CellType != "markdown" {
+			continue
+		}
+		for _, line := range strings.Split(c.Source.String(), "\n") {
+			line = strings.TrimSpace(line)
+			if strings.HasPrefix(line, "#") {
+				return strings.TrimSpace(strings.TrimLeft(line, "#"))
+			}
+		}
+	}
+	return ""
+}
+
+// Language returns the programming language of the notebook's kernel
+func (nb *Notebook) Language() string {
+	if nb.Metadata.LanguageInfo != nil && nb.Metadata.LanguageInfo.Name != "" {
+		return nb.Metadata.LanguageInfo.Name
+	}
+	if nb.Metadata.KernelSpec != nil {
+		return nb.Metadata.KernelSpec.Language
+	}
+	return ""
+}
+
+// HasOutputs reports whether any code cell has outputs or an execution count
+func (nb *Notebook) HasOutputs() bool {
+	for _, c := range nb.Cells {
+		if len(c.Outputs) > 0 || c.ExecutionCount != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// volatileCellMetadata lists cell metadata that only records how the notebook
+// was last run or displayed
+var volatileCellMetadata = []string{"collapsed", "scrolled", "execution", "ExecuteTime"}
+
+// volatileNotebookMetadata lists notebook metadata that records the state of
+// the last kernel session
+var volatileNotebookMetadata = []string{"widgets", "signature"}
+
+// StripOutputs removes all outputs and execution counts along with metadata
+// that only describes the last run of the notebook
+func (nb *Notebook) StripOutputs() {
+	for i := range nb.Cells {
+		c := &nb.Cells[i]
+		if c.CellType == "code" {
+			c.Outputs = []Output{}
+			c.ExecutionCount = nil
+		}
+		for _, key := range volatileCellMetadata {
+			delete(c.Metadata, key)
+		}
+	}
+	for _, key := range volatileNotebookMetadata {
+		delete(nb.Metadata.Extra, key)
+	}
+}
@@ -0,0 +1,175 @@
+package notebook
+
+import (
+	"testing"
+)
+
+// roundTripNotebook is written the way Jupyter writes notebooks
+const roundTripNotebook = `{
+ "cells": [
+  {
+   "cell_type": "markdown",
+   "id": "a1",
+   "metadata": {},
+   "source": [
+    "# Lecture 1: Über <b>intro</b>\n",
+    "text & more"
+   ]
+  },
+  {
+   "cell_type": "code",
+   "execution_count": 3,
+   "id": "b2",
+   "metadata": {
+    "scrolled": true,
+    "tags": [
+     "hide"
+    ]
+   },
+   "outputs": [
+    {
+     "name": "stdout",
+     "output_type": "stream",
+     "text": [
+      "hello\n"
+     ]
+    },
+    {
+     "data": {
+      "application/json": {
+       "a": [
+        1,
+        2.5,
+        null
+       ]
+      },
+      "text/html": [
+       "<div>1.5</div>"
+      ],
+      "text/plain": [
+       "1.5"
+      ]
+     },
+     "execution_count": 3,
+     "metadata": {},
+     "output_type": "execute_result"
+    },
+    {
+     "ename": "ValueError",
+     "evalue": "bad",
+     "output_type": "error",
+     "traceback": [
+      "\u001b[0;31mValueError\u001b[0m"
+     ]
+    }
+   ],
+   "source": "x = 1.5\nx"
+  },
+  {
+   "cell_type": "raw",
+   "id": "c3",
+   "metadata": {
+    "format": "text/x-python"
+   },
+   "source": []
+  }
+ ],
+ "metadata": {
+  "custom": {
+   "k": true
+  },
+  "kernelspec": {
+   "display_name": "Python 3",
+   "language": "python",
+   "name": "python3"
+  },
+  "language_info": {
+   "codemirror_mode": {
+    "name": "ipython",
+    "version": 3
+   },
+   "name": "python",
+   "version": "3.11.4"
+  }
+ },
+ "nbformat": 4,
+ "nbformat_minor": 5
+}
+`
+
+func TestRoundTrip(t *testing.T) {
+	nb, err := Parse([]byte(roundTripNotebook))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := Marshal(nb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != roundTripNotebook {
+		t.Errorf("round trip changed notebook:\n%s", out)
+	}
+
+	if errs := nb.Validate(); len(errs) != 0 {
+		t.Errorf("expected valid notebook, got %v", errs)
+	}
+	if title := nb.Title(); title != "Lecture 1: Über <b>intro</b>" {
+		t.Errorf("unexpected title %q", title)
+	}
+	if lang := nb.Language(); lang != "python" {
+		t.Errorf("unexpected language %q", lang)
+	}
+	if n := len(nb.Cells[1].Outputs); n != 3 {
+		t.Errorf("expected 3 outputs, got %d", n)
+	}
+	if text, ok := nb.Cells[1].Outputs[1].Data.Text("text/plain"); !ok || text != "1.5" {
+		t.Errorf("unexpected text/plain %q", text)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	nb, err := Parse([]byte(`{
+ "cells": [
+  {"cell_type": "code", "id": "x", "metadata": {}, "source": "", "outputs": [{"output_type": "stream", "name": "stdin", "text": ""}], "execution_count": null},
+  {"cell_type": "code", "id": "x", "metadata": {}, "source": "", "outputs": [], "execution_count": null},
+  {"cell_type": "heading", "metadata": {}, "source": ""}
+ ],
+ "metadata": {},
+ "nbformat": 4,
+ "nbformat_minor": 5
+}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if errs := nb.Validate(); len(errs) != 3 {
+		t.Errorf("expected 3 errors, got %v", errs)
+	}
+}
+
+func TestStripOutputs(t *testing.T) {
+	nb, err := Parse([]byte(roundTripNotebook))
+	if err != nil {
+		t.Fatal(err)
+	}
+	nb.StripOutputs()
+
+	code := nb.Cells[1]
+	if len(code.Outputs) != 0 || code.ExecutionCount != nil {
+		t.Errorf("expected outputs to be stripped, got %d outputs", len(code.Outputs))
+	}
+	if _, ok := code.Metadata["scrolled"]; ok {
+		t.Error("expected scrolled metadata to be removed")
+	}
+	if _, ok := code.Metadata["tags"]; !ok {
+		t.Error("expected tags metadata to be kept")
+	}
+
+	out, err := Marshal(nb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Parse(out); err != nil {
+		t.Errorf("stripped notebook does not parse: %s", err)
+	}
+}
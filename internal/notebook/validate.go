@@ -0,0 +1,80 @@
+package notebook
+
+import (
+	"fmt"
+	"regexp"
+)
+
+var cellIDPattern = regexp.MustCompile(`^[a-zA-Z0-9-_]{1,64}$`)
+
+func contains(list []string, x string) bool {
+	for _, v := range list {
+		if v == x {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks the structure of the notebook against the rules of the
+// nbformat v4 schema that matter for rendering and grading. All problems are
+// returned rather than just the first.
+func (nb *Notebook) Validate() []error {
+	var errs []error
+	errorf := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if nb.NBFormat != 4 {
+		errorf("unsupported nbformat %d", nb.NBFormat)
+	}
+	if nb.Metadata.KernelSpec != nil && nb.Metadata.KernelSpec.Name == "" {
+		errorf("kernelspec has no name")
+	}
+
+	ids := make(map[string]int)
+	for i, c := range nb.Cells {
+		cell := i + 1
+		if !contains(CellTypes[:], c.CellType) {
+			errorf("cell %d: invalid cell_type %q", cell, c.CellType)
+			continue
+		}
+
+		if c.ID != "" {
+			if !cellIDPattern.MatchString(c.ID) {
+				errorf("cell %d: invalid id %q", cell, c.ID)
+			}
+			if first, ok := ids[c.ID]; ok {
+				errorf("cell %d: id %q is already used by cell %d", cell, c.ID, first)
+			}
+			ids[c.ID] = cell
+		} else if nb.NBFormatMinor >= 5 {
+			errorf("cell %d: missing id, required since nbformat 4.5", cell)
+		}
+
+		if c.CellType != "code" {
+			continue
+		}
+		for j, o := range c.Outputs {
+			output := j + 1
+			switch o.OutputType {
+			case "stream":
+				if o.Name != "stdout" && o.Name != "stderr" {
+					errorf("cell %d output %d: invalid stream name %q", cell, output, o.Name)
+				}
+			case "display_data", "execute_result":
+				if len(o.Data) == 0 {
+					errorf("cell %d output %d: %s has no data", cell, output, o.OutputType)
+				}
+			case "error":
+				if o.EName == "" {
+					errorf("cell %d output %d: error has no ename", cell, output)
+				}
+			default:
+				errorf("cell %d output %d: invalid output_type %q", cell, output, o.OutputType)
+			}
+		}
+	}
+
+	return errs
+}
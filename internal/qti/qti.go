@@ -0,0 +1,288 @@
+// Package qti writes quizzes as IMS QTI 2.1 content packages, which most
+// learning management systems can import
+package qti
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/sglyon/jupyteach/internal/contentpkg"
+	"github.com/sglyon/jupyteach/internal/model"
+)
+
+const (
+	qtiNamespace       = "http://www.imsglobal.org/xsd/imsqti_v2p1"
+	qtiSchemaLocation  = "http://www.imsglobal.org/xsd/imsqti_v2p1 http://www.imsglobal.org/xsd/qti/qtiv2p1/imsqti_v2p1.xsd"
+	cpNamespace        = "http://www.imsglobal.org/xsd/imscp_v1p1"
+	cpSchemaLocation   = "http://www.imsglobal.org/xsd/imscp_v1p1 http://www.imsglobal.org/xsd/qti/qtiv2p1/qtiv2p1_imscpv1p2_v1p0.xsd"
+	xsiNamespace       = "http://www.w3.org/2001/XMLSchema-instance"
+	itemResourceType   = "imsqti_item_xmlv2p1"
+	testResourceType   = "imsqti_test_xmlv2p1"
+	responseIdentifier = "RESPONSE"
+)
+
+type resource struct {
+	Identifier string
+	Type       string
+	Href       string
+	Depends    []string
+}
+
+// Package collects quizzes to be written as a QTI 2.1 content package. Each
+// quiz becomes an assessment test with one item per question.
+type Package struct {
+	Identifier string
+	files      []contentpkg.File
+	resources  []resource
+	ids        map[string]int
+}
+
+// NewPackage returns an empty package
+func NewPackage(identifier string) *Package {
+	return &Package{Identifier: Identifier(identifier), ids: map[string]int{}}
+}
+
+var invalidIdentifierChars = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// Identifier turns s into a valid QTI identifier (an xml NCName)
+func Identifier(s string) string {
+	id := strings.Trim(invalidIdentifierChars.ReplaceAllString(s, "-"), "-.")
+	if id == "" || !(id[0] == '_' || (id[0] >= 'A' && id[0] <= 'Z') || (id[0] >= 'a' && id[0] <= 'z')) {
+		id = "id-" + id
+	}
+	return id
+}
+
+// unique makes sure every identifier in the package is only used once
+func (p *Package) unique(id string) string {
+	p.ids[id]++
+	if n := p.ids[id]; n > 1 {
+		return fmt.Sprintf("%s-%d", id, n)
+	}
+	return id
+}
+
+// AddQuiz adds the questions of quiz as an assessment test
+func (p *Package) AddQuiz(identifier, title string, quiz model.Quiz) error {
+	testID := p.unique("test-" + Identifier(identifier))
+	test := assessmentTest{
+		Xmlns:          qtiNamespace,
+		Xsi:            xsiNamespace,
+		SchemaLocation: qtiSchemaLocation,
+		Identifier:     testID,
+		Title:          title,
+		Outcomes: []outcomeDeclaration{
+			{Identifier: "SCORE", Cardinality: "single", BaseType: "float"},
+		},
+		TestPart: testPart{
+			Identifier:     "part-1",
+			NavigationMode: "nonlinear",
+			SubmissionMode: "simultaneous",
+			Section: assessmentSection{
+				Identifier: "section-1",
+				Title:      title,
+				Visible:    true,
+			},
+		},
+		OutcomeProcessing: scoreSum,
+	}
+	if strings.TrimSpace(quiz.StartCode) != "" {
+		test.TestPart.Section.Rubric = &rubricBlock{
+			View: "candidate",
+			Body: "<p>Code run before every question:</p>" + preformatted(quiz.StartCode),
+		}
+	}
+
+	var depends []string
+	for i, q := range quiz.Questions {
+		itemID := p.unique(fmt.Sprintf("item-%s-q%d", Identifier(identifier), i+1))
+		item, err := newItem(itemID, fmt.Sprintf("%s, question %d", title, i+1), q)
+		if err != nil {
+			return fmt.Errorf("%s question %d: %w", title, i+1, err)
+		}
+		href := "items/" + itemID + ".xml"
+		if err := p.addXML(href, item); err != nil {
+			return err
+		}
+		p.resources = append(p.resources, resource{Identifier: itemID, Type: itemResourceType, Href: href})
+		test.TestPart.Section.Items = append(test.TestPart.Section.Items, assessmentItemRef{
+			Identifier: itemID,
+			Href:       "../" + href,
+		})
+		depends = append(depends, itemID)
+	}
+
+	href := "tests/" + testID + ".xml"
+	if err := p.addXML(href, test); err != nil {
+		return err
+	}
+	p.resources = append(p.resources, resource{Identifier: testID, Type: testResourceType, Href: href, Depends: depends})
+	return nil
+}
+
+func (p *Package) addXML(href string, v interface{}) error {
+	content, err := contentpkg.MarshalXML(v)
+	if err != nil {
+		return err
+	}
+	p.files = append(p.files, contentpkg.File{Href: href, Content: content})
+	return nil
+}
+
+// Tests returns the number of assessment tests in the package
+func (p *Package) Tests() int {
+	n := 0
+	for _, r := range p.resources {
+		if r.Type == testResourceType {
+			n++
+		}
+	}
+	return n
+}
+
+// WriteZip writes the package, with its imsmanifest.xml, as a zip file
+func (p *Package) WriteZip(w io.Writer) error {
+	return contentpkg.WriteZip(w, p.manifest(), p.files...)
+}
+
+// newItem converts a question to an assessment item. Selection questions are
+// scored automatically, everything else becomes an extended text item to be
+// graded by hand.
+func newItem(identifier, title string, q model.Question) (assessmentItem, error) {
+	item := assessmentItem{
+		Xmlns:          qtiNamespace,
+		Xsi:            xsiNamespace,
+		SchemaLocation: qtiSchemaLocation,
+		Identifier:     identifier,
+		Title:          title,
+		Outcomes: []outcomeDeclaration{
+			{Identifier: "SCORE", Cardinality: "single", BaseType: "float", Default: &values{Values: []string{"0"}}},
+			{Identifier: "MAXSCORE", Cardinality: "single", BaseType: "float", Default: &values{Values: []string{strconv.Itoa(q.Points)}}},
+		},
+	}
+	body := textToXHTML(q.QuestionText)
+
+	switch q.QuestionType {
+	case "single_selection", "multiple_selection":
+		correct := []string{q.Solution}
+		cardinality, maxChoices := "single", 1
+		if q.QuestionType == "multiple_selection" {
+			var err error
+			if correct, err = model.ParseMultipleSelectionSolution(q.Solution); err != nil {
+				return item, err
+			}
+			cardinality, maxChoices = "multiple", 0
+		}
+
+		var choices strings.Builder
+		var correctIDs []string
+		for i, option := range q.Options {
+			id := fmt.Sprintf("choice-%d", i+1)
+			fmt.Fprintf(&choices, "<simpleChoice identifier=%q>%s</simpleChoice>", id, escape(option))
+			for _, c := range correct {
+				if c == option {
+					correctIDs = append(correctIDs, id)
+					break
+				}
+			}
+		}
+		if len(correctIDs) == 0 {
+			return item, fmt.Errorf("solution does not match any of the options")
+		}
+
+		item.Responses = []responseDeclaration{{
+			Identifier:  responseIdentifier,
+			Cardinality: cardinality,
+			BaseType:    "identifier",
+			Correct:     &values{Values: correctIDs},
+		}}
+		body += fmt.Sprintf(
+			`<choiceInteraction responseIdentifier=%q shuffle="false" maxChoices="%d">%s</choiceInteraction>`,
+			responseIdentifier, maxChoices, choices.String(),
+		)
+		item.Processing = &matchCorrect
+	default:
+		response := responseDeclaration{
+			Identifier:  responseIdentifier,
+			Cardinality: "single",
+			BaseType:    "string",
+		}
+		if q.Solution != "" {
+			response.Correct = &values{Values: []string{q.Solution}}
+		}
+
+		format := "plain"
+		expectedLines := 5
+		if q.QuestionType == "code" || q.QuestionType == "fill_in_blank" {
+			format = "preFormatted"
+		}
+		if q.StartingCode != "" {
+			// the starting code is shown with the question and pre-fills the answer
+			format = "preFormatted"
+			response.Default = &values{Values: []string{q.StartingCode}}
+			body += preformatted(q.StartingCode)
+			if n := strings.Count(q.StartingCode, "\n") + 2; n > expectedLines {
+				expectedLines = n
+			}
+		}
+		item.Responses = []responseDeclaration{response}
+		body += fmt.Sprintf(
+			`<extendedTextInteraction responseIdentifier=%q format=%q expectedLines="%d"/>`,
+			responseIdentifier, format, expectedLines,
+		)
+	}
+
+	item.Body = itemBody{Inner: body}
+	return item, nil
+}
+
+// xmlEscaper escapes text for element content and attributes, unlike
+// xml.EscapeText it leaves newlines alone so code stays readable
+var xmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
+
+func escape(s string) string {
+	return xmlEscaper.Replace(s)
+}
+
+func preformatted(code string) string {
+	return "<pre>" + escape(strings.TrimRight(code, "\n")) + "</pre>"
+}
+
+var fencePattern = regexp.MustCompile("^(```+|~~~+)")
+
+// textToXHTML converts question text to the XHTML used in item bodies.
+// Fenced code blocks become pre elements and everything else is split into
+// paragraphs on blank lines.
+func textToXHTML(text string) string {
+	var out, para strings.Builder
+	flush := func() {
+		if s := strings.TrimSpace(para.String()); s != "" {
+			out.WriteString("<p>" + escape(s) + "</p>")
+		}
+		para.Reset()
+	}
+
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		fence := fencePattern.FindString(strings.TrimSpace(line))
+		switch {
+		case fence != "":
+			flush()
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), fence); i++ {
+				code = append(code, lines[i])
+			}
+			out.WriteString(preformatted(strings.Join(code, "\n")))
+		case strings.TrimSpace(line) == "":
+			flush()
+		default:
+			para.WriteString(line + "\n")
+		}
+	}
+	flush()
+	return out.String()
+}
@@ -0,0 +1,93 @@
+package qti
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/sglyon/jupyteach/internal/model"
+)
+
+func TestWriteZip(t *testing.T) {
+	quiz := model.Quiz{Questions: []model.Question{
+		{QuestionType: "single_selection", QuestionText: "Pick <one>", Points: 2, Options: []string{"a", "b"}, Solution: "b"},
+		{QuestionType: "multiple_selection", QuestionText: "Pick many", Points: 1, Options: []string{"a", "b", "c"}, Solution: `["a", "c"]`},
+		{QuestionType: "code", QuestionText: "Write add\n\n```python\nadd(1, 2)\n```", Points: 5, StartingCode: "def add(a, b):\n    pass\n", Solution: "def add(a, b):\n    return a + b\n", TestCode: "assert add(1, 2) == 3"},
+	}}
+
+	pkg := NewPackage("01 course")
+	if err := pkg.AddQuiz("01-intro-quiz-1", "Intro", quiz); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := pkg.WriteZip(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(content)
+
+		// every file must be well formed xml
+		dec := xml.NewDecoder(bytes.NewReader(content))
+		for {
+			if _, err := dec.Token(); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("%s is not valid xml: %v", f.Name, err)
+			}
+		}
+	}
+
+	for _, name := range []string{
+		"imsmanifest.xml",
+		"tests/test-id-01-intro-quiz-1.xml",
+		"items/item-id-01-intro-quiz-1-q1.xml",
+		"items/item-id-01-intro-quiz-1-q2.xml",
+		"items/item-id-01-intro-quiz-1-q3.xml",
+	} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("missing %s in package", name)
+		}
+	}
+
+	checks := map[string][]string{
+		"items/item-id-01-intro-quiz-1-q1.xml": {`<p>Pick &lt;one&gt;</p>`, `<value>choice-2</value>`, `maxChoices="1"`},
+		"items/item-id-01-intro-quiz-1-q2.xml": {`cardinality="multiple"`, `<value>choice-1</value>`, `<value>choice-3</value>`},
+		"items/item-id-01-intro-quiz-1-q3.xml": {`<extendedTextInteraction`, `<pre>add(1, 2)</pre>`, "<pre>def add(a, b):\n    pass</pre>"},
+	}
+	for name, wants := range checks {
+		for _, want := range wants {
+			if !strings.Contains(files[name], want) {
+				t.Errorf("%s does not contain %q:\n%s", name, want, files[name])
+			}
+		}
+	}
+}
+
+func TestIdentifier(t *testing.T) {
+	cases := map[string]string{
+		"intro":       "intro",
+		"01 Intro!":   "id-01-Intro",
+		"":            "id-",
+		"week 1/quiz": "week-1-quiz",
+	}
+	for in, want := range cases {
+		if got := Identifier(in); got != want {
+			t.Errorf("Identifier(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
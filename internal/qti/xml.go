@@ -0,0 +1,154 @@
+package qti
+
+import "encoding/xml"
+
+type values struct {
+	Values []string `xml:"value"`
+}
+
+type outcomeDeclaration struct {
+	Identifier  string  `xml:"identifier,attr"`
+	Cardinality string  `xml:"cardinality,attr"`
+	BaseType    string  `xml:"baseType,attr"`
+	Default     *values `xml:"defaultValue,omitempty"`
+}
+
+type responseDeclaration struct {
+	Identifier  string  `xml:"identifier,attr"`
+	Cardinality string  `xml:"cardinality,attr"`
+	BaseType    string  `xml:"baseType,attr"`
+	Default     *values `xml:"defaultValue,omitempty"`
+	Correct     *values `xml:"correctResponse,omitempty"`
+}
+
+// itemBody holds the XHTML and interactions of an item, which are built as
+// text
+type itemBody struct {
+	Inner string `xml:",innerxml"`
+}
+
+type responseProcessing struct {
+	Inner string `xml:",innerxml"`
+}
+
+// matchCorrect awards MAXSCORE when the response matches the correct response
+var matchCorrect = responseProcessing{Inner: `<responseCondition>` +
+	`<responseIf><match><variable identifier="RESPONSE"/><correct identifier="RESPONSE"/></match>` +
+	`<setOutcomeValue identifier="SCORE"><variable identifier="MAXSCORE"/></setOutcomeValue></responseIf>` +
+	`</responseCondition>`}
+
+type assessmentItem struct {
+	XMLName        xml.Name              `xml:"assessmentItem"`
+	Xmlns          string                `xml:"xmlns,attr"`
+	Xsi            string                `xml:"xmlns:xsi,attr"`
+	SchemaLocation string                `xml:"xsi:schemaLocation,attr"`
+	Identifier     string                `xml:"identifier,attr"`
+	Title          string                `xml:"title,attr"`
+	Adaptive       bool                  `xml:"adaptive,attr"`
+	TimeDependent  bool                  `xml:"timeDependent,attr"`
+	Responses      []responseDeclaration `xml:"responseDeclaration"`
+	Outcomes       []outcomeDeclaration  `xml:"outcomeDeclaration"`
+	Body           itemBody              `xml:"itemBody"`
+	Processing     *responseProcessing   `xml:"responseProcessing,omitempty"`
+}
+
+type assessmentItemRef struct {
+	Identifier string `xml:"identifier,attr"`
+	Href       string `xml:"href,attr"`
+}
+
+type rubricBlock struct {
+	View string `xml:"view,attr"`
+	Body string `xml:",innerxml"`
+}
+
+type assessmentSection struct {
+	Identifier string              `xml:"identifier,attr"`
+	Title      string              `xml:"title,attr"`
+	Visible    bool                `xml:"visible,attr"`
+	Rubric     *rubricBlock        `xml:"rubricBlock,omitempty"`
+	Items      []assessmentItemRef `xml:"assessmentItemRef"`
+}
+
+type testPart struct {
+	Identifier     string            `xml:"identifier,attr"`
+	NavigationMode string            `xml:"navigationMode,attr"`
+	SubmissionMode string            `xml:"submissionMode,attr"`
+	Section        assessmentSection `xml:"assessmentSection"`
+}
+
+type outcomeProcessing struct {
+	Inner string `xml:",innerxml"`
+}
+
+// scoreSum makes the score of a test the sum of the scores of its items
+var scoreSum = outcomeProcessing{Inner: `<setOutcomeValue identifier="SCORE">` +
+	`<sum><testVariables variableIdentifier="SCORE"/></sum>` +
+	`</setOutcomeValue>`}
+
+type assessmentTest struct {
+	XMLName           xml.Name             `xml:"assessmentTest"`
+	Xmlns             string               `xml:"xmlns,attr"`
+	Xsi               string               `xml:"xmlns:xsi,attr"`
+	SchemaLocation    string               `xml:"xsi:schemaLocation,attr"`
+	Identifier        string               `xml:"identifier,attr"`
+	Title             string               `xml:"title,attr"`
+	Outcomes          []outcomeDeclaration `xml:"outcomeDeclaration"`
+	TestPart          testPart             `xml:"testPart"`
+	OutcomeProcessing outcomeProcessing    `xml:"outcomeProcessing"`
+}
+
+type manifestFile struct {
+	Href string `xml:"href,attr"`
+}
+
+type manifestDependency struct {
+	IdentifierRef string `xml:"identifierref,attr"`
+}
+
+type manifestResource struct {
+	Identifier   string               `xml:"identifier,attr"`
+	Type         string               `xml:"type,attr"`
+	Href         string               `xml:"href,attr"`
+	Files        []manifestFile       `xml:"file"`
+	Dependencies []manifestDependency `xml:"dependency"`
+}
+
+type manifestMetadata struct {
+	Schema        string `xml:"schema"`
+	SchemaVersion string `xml:"schemaversion"`
+}
+
+type manifest struct {
+	XMLName        xml.Name           `xml:"manifest"`
+	Xmlns          string             `xml:"xmlns,attr"`
+	Xsi            string             `xml:"xmlns:xsi,attr"`
+	SchemaLocation string             `xml:"xsi:schemaLocation,attr"`
+	Identifier     string             `xml:"identifier,attr"`
+	Metadata       manifestMetadata   `xml:"metadata"`
+	Organizations  struct{}           `xml:"organizations"`
+	Resources      []manifestResource `xml:"resources>resource"`
+}
+
+func (p *Package) manifest() manifest {
+	m := manifest{
+		Xmlns:          cpNamespace,
+		Xsi:            xsiNamespace,
+		SchemaLocation: cpSchemaLocation,
+		Identifier:     "manifest-" + p.Identifier,
+		Metadata:       manifestMetadata{Schema: "QTIv2.1 Package", SchemaVersion: "1.0.0"},
+	}
+	for _, r := range p.resources {
+		mr := manifestResource{
+			Identifier: r.Identifier,
+			Type:       r.Type,
+			Href:       r.Href,
+			Files:      []manifestFile{{Href: r.Href}},
+		}
+		for _, d := range r.Depends {
+			mr.Dependencies = append(mr.Dependencies, manifestDependency{IdentifierRef: d})
+		}
+		m.Resources = append(m.Resources, mr)
+	}
+	return m
+}
@@ -0,0 +1,75 @@
+package render
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sglyon/jupyteach/internal/notebook"
+)
+
+// LocalFiles returns the files of dir, relative to it, that a page made from
+// the markdown files and notebooks names needs: names themselves and the local
+// files they link to or embed, following the links of linked markdown files
+// and notebooks in turn. Files outside of dir, hidden files, missing files
+// and files exclude reports are left out.
+func LocalFiles(dir string, names []string, exclude func(name string) bool) []string {
+	var files []string
+	queue := make([]string, 0, len(names))
+	for _, name := range names {
+		queue = append(queue, filepath.Clean(filepath.FromSlash(name)))
+	}
+	seen := map[string]bool{}
+	for len(queue) > 0 {
+		rel := queue[0]
+		queue = queue[1:]
+		if seen[rel] || !filepath.IsLocal(rel) || hiddenPath(rel) || (exclude != nil && exclude(rel)) {
+			continue
+		}
+		seen[rel] = true
+
+		content, err := os.ReadFile(filepath.Join(dir, rel))
+		if err != nil {
+			// a link to a missing file is simply broken
+			continue
+		}
+		files = append(files, rel)
+		for _, link := range fileLinks(rel, content) {
+			queue = append(queue, filepath.Clean(filepath.Join(filepath.Dir(rel), filepath.FromSlash(link))))
+		}
+	}
+	return files
+}
+
+// fileLinks returns the local paths a markdown file or the markdown cells of
+// a notebook link to, relative to the file
+func fileLinks(name string, content []byte) []string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".md", ".markdown":
+		return LocalLinks(content)
+	case ".ipynb":
+		nb, err := notebook.Parse(content)
+		if err != nil {
+			return nil
+		}
+		var links []string
+		for _, c := range nb.Cells {
+			if c.CellType == "markdown" {
+				links = append(links, LocalLinks([]byte(c.Source.String()))...)
+			}
+		}
+		return links
+	}
+	return nil
+}
+
+// hiddenPath reports whether any part of the relative path rel is hidden,
+// starting with a dot or ending with ~ like editor backups
+func hiddenPath(rel string) bool {
+	for _, part := range strings.Split(rel, string(filepath.Separator)) {
+		if strings.HasPrefix(part, ".") || strings.HasSuffix(part, "~") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,118 @@
+// Package render turns course content into HTML
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	gmhtml "github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+)
+
+// markdown keeps raw HTML, which course authors use in markdown cells just
+// like in Jupyter
+var markdown = goldmark.New(
+	goldmark.WithExtensions(extension.GFM),
+	goldmark.WithRendererOptions(gmhtml.WithUnsafe()),
+)
+
+// Markdown renders github flavored markdown to an HTML fragment
+func Markdown(src []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := markdown.Convert(src, &buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Page wraps an HTML fragment in a standalone HTML document. Any head
+// elements (scripts, stylesheets) are added to the document head.
+func Page(title string, body []byte, head ...string) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n", html.EscapeString(title))
+	for _, h := range head {
+		buf.WriteString(h + "\n")
+	}
+	buf.WriteString("</head>\n<body>\n")
+	buf.Write(body)
+	buf.WriteString("</body>\n</html>\n")
+	return buf.Bytes()
+}
+
+// htmlRef finds the src and href attributes of raw HTML
+var htmlRef = regexp.MustCompile(`(?i)\b(?:src|href)\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+
+// LocalLinks returns the relative paths markdown links to or embeds, through
+// links, images or raw HTML, with any query or fragment removed. Addresses
+// with a scheme, absolute paths and fragments of the page itself are left
+// out.
+func LocalLinks(src []byte) []string {
+	var dests []string
+	raw := func(n ast.Node) {
+		var buf bytes.Buffer
+		var lines *text.Segments
+		if r, ok := n.(*ast.RawHTML); ok {
+			lines = r.Segments
+		} else {
+			lines = n.Lines()
+		}
+		for i := 0; i < lines.Len(); i++ {
+			seg := lines.At(i)
+			buf.Write(seg.Value(src))
+		}
+		for _, m := range htmlRef.FindAllStringSubmatch(buf.String(), -1) {
+			dests = append(dests, m[1]+m[2])
+		}
+	}
+
+	doc := markdown.Parser().Parse(text.NewReader(src))
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n := n.(type) {
+		case *ast.Link:
+			dests = append(dests, string(n.Destination))
+		case *ast.Image:
+			dests = append(dests, string(n.Destination))
+		case *ast.HTMLBlock, *ast.RawHTML:
+			raw(n)
+		}
+		return ast.WalkContinue, nil
+	})
+
+	var out []string
+	for _, d := range dests {
+		if p, ok := localPath(d); ok {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// localPath turns a link destination into a relative file path, reporting
+// false for links that don't point at a file next to the page
+func localPath(dest string) (string, bool) {
+	dest = strings.TrimSpace(dest)
+	if i := strings.IndexAny(dest, "?#"); i >= 0 {
+		dest = dest[:i]
+	}
+	if dest == "" || strings.HasPrefix(dest, "/") || strings.HasPrefix(dest, "\\") {
+		return "", false
+	}
+	if u, err := url.Parse(dest); err != nil || u.Scheme != "" || u.Host != "" {
+		return "", false
+	}
+	p, err := url.PathUnescape(dest)
+	if err != nil {
+		return "", false
+	}
+	return p, true
+}
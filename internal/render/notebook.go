@@ -0,0 +1,91 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/sglyon/jupyteach/internal/notebook"
+)
+
+// ansiEscape matches the terminal color codes found in tracebacks
+var ansiEscape = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// imageTypes are the image outputs that can be embedded, in order of preference
+var imageTypes = []string{"image/svg+xml", "image/png", "image/jpeg", "image/gif"}
+
+// Notebook renders the cells of a notebook, and their outputs, to an HTML
+// fragment
+func Notebook(nb *notebook.Notebook) ([]byte, error) {
+	var buf bytes.Buffer
+	codeClass := ""
+	if language := nb.Language(); language != "" {
+		codeClass = fmt.Sprintf(" class=\"language-%s\"", html.EscapeString(language))
+	}
+	for _, cell := range nb.Cells {
+		source := cell.Source.String()
+		switch cell.CellType {
+		case "markdown":
+			out, err := Markdown([]byte(source))
+			if err != nil {
+				return nil, err
+			}
+			buf.WriteString(`<div class="cell markdown">` + "\n")
+			buf.Write(out)
+			buf.WriteString("</div>\n")
+		case "code":
+			buf.WriteString(`<div class="cell code">` + "\n")
+			fmt.Fprintf(&buf, "<pre class=\"input\"><code%s>%s</code></pre>\n", codeClass, html.EscapeString(source))
+			for _, output := range cell.Outputs {
+				renderOutput(&buf, output)
+			}
+			buf.WriteString("</div>\n")
+		case "raw":
+			fmt.Fprintf(&buf, "<pre class=\"cell raw\">%s</pre>\n", html.EscapeString(source))
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+func renderOutput(buf *bytes.Buffer, output notebook.Output) {
+	switch output.OutputType {
+	case "stream":
+		fmt.Fprintf(buf, "<pre class=\"output %s\">%s</pre>\n", html.EscapeString(output.Name), html.EscapeString(output.Text.String()))
+	case "error":
+		traceback := ansiEscape.ReplaceAllString(strings.Join(output.Traceback, "\n"), "")
+		if traceback == "" {
+			traceback = output.EName + ": " + output.EValue
+		}
+		fmt.Fprintf(buf, "<pre class=\"output error\">%s</pre>\n", html.EscapeString(traceback))
+	case "display_data", "execute_result":
+		if s, ok := output.Data.Text("text/html"); ok {
+			buf.WriteString(`<div class="output html">` + s + "</div>\n")
+			return
+		}
+		for _, mime := range imageTypes {
+			s, ok := output.Data.Text(mime)
+			if !ok {
+				continue
+			}
+			if mime == "image/svg+xml" {
+				buf.WriteString(`<div class="output image">` + s + "</div>\n")
+			} else {
+				fmt.Fprintf(buf, "<div class=\"output image\"><img src=\"data:%s;base64,%s\"></div>\n", mime, strings.TrimSpace(s))
+			}
+			return
+		}
+		if s, ok := output.Data.Text("text/markdown"); ok {
+			if out, err := Markdown([]byte(s)); err == nil {
+				buf.WriteString(`<div class="output markdown">`)
+				buf.Write(out)
+				buf.WriteString("</div>\n")
+				return
+			}
+		}
+		if s, ok := output.Data.Text("text/plain"); ok {
+			fmt.Fprintf(buf, "<pre class=\"output\">%s</pre>\n", html.EscapeString(s))
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package render
+
+import (
+	"fmt"
+	"html"
+
+	"github.com/sglyon/jupyteach/internal/model"
+)
+
+// Video returns an HTML fragment playing the video of a video content block
+func Video(cb model.ContentBlockYaml) []byte {
+	switch {
+	case cb.YoutubeVideoID != "":
+		return iframe("https://www.youtube.com/embed/" + cb.YoutubeVideoID)
+	case cb.VimeoVideoID != "":
+		return iframe("https://player.vimeo.com/video/" + cb.VimeoVideoID)
+	}
+	return []byte(fmt.Sprintf(
+		"<video controls width=\"640\" src=\"%s\"><a href=\"%[1]s\">%[1]s</a></video>\n",
+		html.EscapeString(cb.URL),
+	))
+}
+
+func iframe(src string) []byte {
+	return []byte(fmt.Sprintf(
+		"<iframe width=\"640\" height=\"360\" src=\"%s\" frameborder=\"0\" allow=\"fullscreen; picture-in-picture\" allowfullscreen></iframe>\n",
+		html.EscapeString(src),
+	))
+}
@@ -0,0 +1,151 @@
+// Package runner executes the code of quiz questions locally so autograder
+// tests can be checked before a quiz is published
+package runner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/sglyon/jupyteach/internal/model"
+)
+
+// Result is the outcome of running a single program
+type Result struct {
+	Passed bool
+	Output string
+}
+
+// Runner executes a program in dir and reports whether it succeeded
+type Runner interface {
+	Run(ctx context.Context, dir, code string) (Result, error)
+}
+
+// CommandRunner writes the program to a temporary file and runs Command with
+// the file path appended as the last argument. The program passes when the
+// command exits with status 0.
+type CommandRunner struct {
+	Command   []string
+	Extension string
+	Timeout   time.Duration
+}
+
+// NewPythonRunner returns a runner that executes code with the given python
+// interpreter
+func NewPythonRunner(interpreter string, timeout time.Duration) *CommandRunner {
+	return &CommandRunner{Command: []string{interpreter}, Extension: ".py", Timeout: timeout}
+}
+
+// NewCommandRunner returns a runner for a whitespace separated command line
+// such as "conda run -n course python"
+func NewCommandRunner(command string, timeout time.Duration) (*CommandRunner, error) {
+	parts := strings.Fields(command)
+	if len(parts) == 0 {
+		return nil, errors.New("runner command is empty")
+	}
+	return &CommandRunner{Command: parts, Extension: ".py", Timeout: timeout}, nil
+}
+
+func (r *CommandRunner) Run(ctx context.Context, dir, code string) (Result, error) {
+	f, err := os.CreateTemp("", "jupyteach-quiz-*"+r.Extension)
+	if err != nil {
+		return Result{}, err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString(code); err != nil {
+		f.Close()
+		return Result{}, err
+	}
+	if err := f.Close(); err != nil {
+		return Result{}, err
+	}
+
+	if r.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.Timeout)
+		defer cancel()
+	}
+
+	args := append(append([]string{}, r.Command[1:]...), f.Name())
+	cmd := exec.CommandContext(ctx, r.Command[0], args...)
+	cmd.Dir = dir
+	// don't wait for children of a killed program that still hold its output
+	cmd.WaitDelay = time.Second
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+
+	err = cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return Result{Passed: false, Output: out.String() + "\ntimed out after " + r.Timeout.String()}, nil
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return Result{Passed: false, Output: out.String()}, nil
+	}
+	if err != nil {
+		// the command itself could not be started
+		return Result{}, err
+	}
+	return Result{Passed: true, Output: out.String()}, nil
+}
+
+// joinCode concatenates the non empty code fragments into a single program
+func joinCode(parts ...string) string {
+	var kept []string
+	for _, p := range parts {
+		if strings.TrimSpace(p) != "" {
+			kept = append(kept, strings.TrimRight(p, "\n"))
+		}
+	}
+	return strings.Join(kept, "\n\n") + "\n"
+}
+
+// QuestionResult is the outcome of testing a single code question
+type QuestionResult struct {
+	Index    int
+	Question model.Question
+	// Solution is the result of running setup + solution + tests, which must pass
+	Solution Result
+	// Starting is the result of running setup + starting code + tests, which
+	// must fail. It is nil when the question has no starting code.
+	Starting *Result
+}
+
+// Passed reports whether the solution passes the tests and the starting code
+// does not
+func (r QuestionResult) Passed() bool {
+	return r.Solution.Passed && (r.Starting == nil || !r.Starting.Passed)
+}
+
+// TestQuiz runs every code question of quiz in dir. The quiz start code is
+// run ahead of each question's setup code.
+func TestQuiz(ctx context.Context, r Runner, dir string, quiz model.Quiz) ([]QuestionResult, error) {
+	var results []QuestionResult
+	for i, q := range quiz.Questions {
+		if q.QuestionType != "code" {
+			continue
+		}
+		res := QuestionResult{Index: i, Question: q}
+
+		solution, err := r.Run(ctx, dir, joinCode(quiz.StartCode, q.SetupCode, q.Solution, q.TestCode))
+		if err != nil {
+			return nil, err
+		}
+		res.Solution = solution
+
+		if strings.TrimSpace(q.StartingCode) != "" {
+			starting, err := r.Run(ctx, dir, joinCode(quiz.StartCode, q.SetupCode, q.StartingCode, q.TestCode))
+			if err != nil {
+				return nil, err
+			}
+			res.Starting = &starting
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
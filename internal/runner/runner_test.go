@@ -0,0 +1,109 @@
+package runner
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sglyon/jupyteach/internal/model"
+)
+
+// fakeRunner passes programs that set answer to 42 and records what it ran
+type fakeRunner struct {
+	programs []string
+}
+
+func (r *fakeRunner) Run(ctx context.Context, dir, code string) (Result, error) {
+	r.programs = append(r.programs, code)
+	return Result{Passed: strings.Contains(code, "answer = 42")}, nil
+}
+
+func TestTestQuiz(t *testing.T) {
+	quiz := model.Quiz{
+		StartCode: "import math",
+		Questions: []model.Question{
+			{QuestionType: "single_selection", QuestionText: "Pick one", Options: []string{"a", "b"}, Solution: "a"},
+			{QuestionType: "code", Solution: "answer = 42", StartingCode: "answer = None", TestCode: "assert answer == 42"},
+			{QuestionType: "code", Solution: "answer = 42", StartingCode: "answer = 42 # TODO", TestCode: "assert answer == 42"},
+			{QuestionType: "code", SetupCode: "x = 1", Solution: "answer = 42", TestCode: "assert answer == 42"},
+			{QuestionType: "code", Solution: "answer = 41", TestCode: "assert answer == 42"},
+		},
+	}
+	r := &fakeRunner{}
+	results, err := TestQuiz(context.Background(), r, t.TempDir(), quiz)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 4 {
+		t.Fatalf("got %d results, want 4 for the code questions", len(results))
+	}
+	for i, want := range []struct {
+		index    int
+		passed   bool
+		starting bool
+	}{
+		{1, true, true},
+		{2, false, true}, // starting code passes the tests
+		{3, true, false},
+		{4, false, false}, // solution fails the tests
+	} {
+		res := results[i]
+		if res.Index != want.index {
+			t.Errorf("result %d is for question %d, want %d", i, res.Index, want.index)
+		}
+		if res.Passed() != want.passed {
+			t.Errorf("question %d: Passed() = %v, want %v", res.Index, res.Passed(), want.passed)
+		}
+		if (res.Starting != nil) != want.starting {
+			t.Errorf("question %d: Starting = %v, want set %v", res.Index, res.Starting, want.starting)
+		}
+	}
+
+	want := "import math\n\nx = 1\n\nanswer = 42\n\nassert answer == 42\n"
+	if got := r.programs[4]; got != want {
+		t.Errorf("program = %q, want %q", got, want)
+	}
+}
+
+func TestJoinCode(t *testing.T) {
+	got := joinCode("", "a = 1\n\n", "  \n", "b = 2")
+	if want := "a = 1\n\nb = 2\n"; got != want {
+		t.Errorf("joinCode = %q, want %q", got, want)
+	}
+}
+
+func TestCommandRunner(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+	r := &CommandRunner{Command: []string{"sh"}, Extension: ".sh", Timeout: 5 * time.Second}
+	ctx := context.Background()
+
+	res, err := r.Run(ctx, t.TempDir(), "echo ok\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Passed || strings.TrimSpace(res.Output) != "ok" {
+		t.Errorf("got %+v, want a pass printing ok", res)
+	}
+
+	res, err = r.Run(ctx, t.TempDir(), "echo failing >&2\nexit 3\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Passed || !strings.Contains(res.Output, "failing") {
+		t.Errorf("got %+v, want a failure with its output", res)
+	}
+
+	r.Timeout = 100 * time.Millisecond
+	res, err = r.Run(ctx, t.TempDir(), "sleep 5\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Passed || !strings.Contains(res.Output, "timed out") {
+		t.Errorf("got %+v, want a time out", res)
+	}
+}
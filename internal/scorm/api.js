@@ -0,0 +1,37 @@
+// Reports progress of a page to the SCORM 1.2 run time of the LMS. Viewing a
+// page marks it as completed.
+(function () {
+  function findAPI(win) {
+    for (var i = 0; win && i < 10; i++) {
+      if (win.API) {
+        return win.API;
+      }
+      if (win.parent === win) {
+        break;
+      }
+      win = win.parent;
+    }
+    return null;
+  }
+
+  var api = findAPI(window) || (window.opener && findAPI(window.opener));
+  if (!api) {
+    return;
+  }
+
+  var finished = false;
+  function finish() {
+    if (finished) {
+      return;
+    }
+    finished = true;
+    api.LMSCommit("");
+    api.LMSFinish("");
+  }
+
+  api.LMSInitialize("");
+  api.LMSSetValue("cmi.core.lesson_status", "completed");
+  api.LMSCommit("");
+  window.addEventListener("beforeunload", finish);
+  window.addEventListener("unload", finish);
+})();
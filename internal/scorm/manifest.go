@@ -0,0 +1,121 @@
+package scorm
+
+import "encoding/xml"
+
+const (
+	cpNamespace    = "http://www.imsproject.org/xsd/imscp_rootv1p1p2"
+	adlcpNamespace = "http://www.adlnet.org/xsd/adlcp_rootv1p2"
+	xsiNamespace   = "http://www.w3.org/2001/XMLSchema-instance"
+	schemaLocation = "http://www.imsproject.org/xsd/imscp_rootv1p1p2 imscp_rootv1p1p2.xsd " +
+		"http://www.imsglobal.org/xsd/imsmd_rootv1p2p1 imsmd_rootv1p2p1.xsd " +
+		"http://www.adlnet.org/xsd/adlcp_rootv1p2 adlcp_rootv1p2.xsd"
+	commonResource = "common"
+)
+
+type manifestMetadata struct {
+	Schema        string `xml:"schema"`
+	SchemaVersion string `xml:"schemaversion"`
+}
+
+type manifestItem struct {
+	Identifier    string         `xml:"identifier,attr"`
+	IdentifierRef string         `xml:"identifierref,attr,omitempty"`
+	Title         string         `xml:"title"`
+	Items         []manifestItem `xml:"item"`
+}
+
+type organization struct {
+	Identifier string         `xml:"identifier,attr"`
+	Title      string         `xml:"title"`
+	Items      []manifestItem `xml:"item"`
+}
+
+type organizations struct {
+	Default       string         `xml:"default,attr"`
+	Organizations []organization `xml:"organization"`
+}
+
+type manifestFile struct {
+	Href string `xml:"href,attr"`
+}
+
+type manifestDependency struct {
+	IdentifierRef string `xml:"identifierref,attr"`
+}
+
+type manifestResource struct {
+	Identifier   string               `xml:"identifier,attr"`
+	Type         string               `xml:"type,attr"`
+	ScormType    string               `xml:"adlcp:scormtype,attr"`
+	Href         string               `xml:"href,attr,omitempty"`
+	Files        []manifestFile       `xml:"file"`
+	Dependencies []manifestDependency `xml:"dependency"`
+}
+
+type manifest struct {
+	XMLName        xml.Name           `xml:"manifest"`
+	Xmlns          string             `xml:"xmlns,attr"`
+	Adlcp          string             `xml:"xmlns:adlcp,attr"`
+	Xsi            string             `xml:"xmlns:xsi,attr"`
+	SchemaLocation string             `xml:"xsi:schemaLocation,attr"`
+	Identifier     string             `xml:"identifier,attr"`
+	Version        string             `xml:"version,attr"`
+	Metadata       manifestMetadata   `xml:"metadata"`
+	Organizations  organizations      `xml:"organizations"`
+	Resources      []manifestResource `xml:"resources>resource"`
+}
+
+func (p *Package) manifest() manifest {
+	org := organization{Identifier: "organization", Title: p.Title}
+	resources := []manifestResource{{
+		Identifier: commonResource,
+		Type:       "webcontent",
+		ScormType:  "asset",
+		Files:      []manifestFile{{Href: apiScriptHref}},
+	}}
+
+	for _, l := range p.lectures {
+		if len(l.SCOs) == 0 {
+			// every leaf item of the organization must launch a SCO
+			continue
+		}
+		item := manifestItem{Identifier: l.Identifier, Title: l.Title}
+		for _, s := range l.SCOs {
+			item.Items = append(item.Items, manifestItem{
+				Identifier:    "item-" + s.Identifier,
+				IdentifierRef: s.Identifier,
+				Title:         s.Title,
+			})
+			resources = append(resources, manifestResource{
+				Identifier:   s.Identifier,
+				Type:         "webcontent",
+				ScormType:    "sco",
+				Href:         s.Href,
+				Files:        manifestFiles(s.Files),
+				Dependencies: []manifestDependency{{IdentifierRef: commonResource}},
+			})
+		}
+		org.Items = append(org.Items, item)
+	}
+
+	return manifest{
+		Xmlns:          cpNamespace,
+		Adlcp:          adlcpNamespace,
+		Xsi:            xsiNamespace,
+		SchemaLocation: schemaLocation,
+		Identifier:     "manifest",
+		Version:        "1.0",
+		Metadata:       manifestMetadata{Schema: "ADL SCORM", SchemaVersion: "1.2"},
+		Organizations:  organizations{Default: org.Identifier, Organizations: []organization{org}},
+		Resources:      resources,
+	}
+}
+
+// manifestFiles lists hrefs as the files of a resource
+func manifestFiles(hrefs []string) []manifestFile {
+	files := make([]manifestFile, len(hrefs))
+	for i, href := range hrefs {
+		files[i] = manifestFile{Href: href}
+	}
+	return files
+}
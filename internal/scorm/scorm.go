@@ -0,0 +1,211 @@
+// Package scorm writes a course as a SCORM 1.2 package. Every markdown,
+// notebook, video and link block is rendered to an HTML page that is its own
+// SCO (sharable content object).
+package scorm
+
+import (
+	_ "embed"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/sglyon/jupyteach/internal/contentpkg"
+	"github.com/sglyon/jupyteach/internal/model"
+	"github.com/sglyon/jupyteach/internal/notebook"
+	"github.com/sglyon/jupyteach/internal/render"
+)
+
+// apiScript is included by every page to report completion to the LMS
+//
+//go:embed api.js
+var apiScript []byte
+
+const apiScriptHref = "scorm.js"
+
+// sco is a rendered page. Files lists the page and the local files it links
+// to or embeds.
+type sco struct {
+	Identifier string
+	Title      string
+	Href       string
+	Files      []string
+}
+
+// lecture groups the pages of a lecture
+type lecture struct {
+	Identifier string
+	Title      string
+	SCOs       []sco
+}
+
+// Package is a course converted to SCORM pages. Lectures become items of the
+// organization, in the order of CourseYaml.Lectures.
+type Package struct {
+	Title    string
+	files    []contentpkg.File
+	lectures []lecture
+	hrefs    map[string]bool
+	n        int
+
+	// Skipped lists content blocks that could not be converted
+	Skipped []string
+}
+
+// uniqueHref makes sure two pages are never written to the same file
+func (p *Package) uniqueHref(href string) string {
+	ext := path.Ext(href)
+	base := strings.TrimSuffix(href, ext)
+	for i := 2; p.hrefs[href]; i++ {
+		href = fmt.Sprintf("%s-%d%s", base, i, ext)
+	}
+	p.hrefs[href] = true
+	return href
+}
+
+// Build converts the course in the directory dirname
+func Build(dirname string, course *model.CourseYaml, opts model.ZipOptions) (*Package, error) {
+	p := &Package{Title: course.Name, hrefs: map[string]bool{apiScriptHref: true}}
+	if p.Title == "" {
+		p.Title = course.Slug
+	}
+	stripOutputs := opts.StripOutputs || course.StripNotebookOutputs
+
+	for _, cl := range course.Lectures {
+		lectureDir := filepath.Join(dirname, cl.Directory)
+		ly, err := model.ParseLectureYaml(filepath.Join(lectureDir, "_lecture.yml"))
+		if err != nil {
+			return nil, err
+		}
+		p.n++
+		l := lecture{Identifier: fmt.Sprintf("lecture-%d", p.n), Title: ly.Title}
+		if l.Title == "" {
+			l.Title = cl.Directory
+		}
+
+		for i, cb := range ly.ContentBlocks {
+			title := cb.Title
+			if title == "" {
+				title = fmt.Sprintf("%s %d", cb.Type, i+1)
+			}
+			body, err := renderBlock(lectureDir, cb, stripOutputs)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s: %w", cl.Directory, title, err)
+			}
+			if body == nil {
+				p.Skipped = append(p.Skipped, fmt.Sprintf("%s: %s (%s)", cl.Directory, title, cb.Type))
+				continue
+			}
+
+			name := model.Slugify(title, "-")
+			if cb.Filename != "" {
+				name = strings.TrimSuffix(cb.Filename, filepath.Ext(cb.Filename))
+			}
+			if name == "" {
+				// titles of only punctuation or symbols have no slug
+				name = fmt.Sprintf("%s-%d", cb.Type, i+1)
+			}
+			assets, err := p.addAssets(lectureDir, filepath.ToSlash(cl.Directory), cb)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s: %w", cl.Directory, title, err)
+			}
+			href := p.uniqueHref(path.Join(filepath.ToSlash(cl.Directory), filepath.ToSlash(name)+".html"))
+			script := strings.Repeat("../", strings.Count(href, "/")) + apiScriptHref
+			page := render.Page(
+				title,
+				append([]byte("<h1>"+html.EscapeString(title)+"</h1>\n"), body...),
+				fmt.Sprintf(`<script src="%s"></script>`, script),
+			)
+			p.files = append(p.files, contentpkg.File{Href: href, Content: page})
+
+			p.n++
+			l.SCOs = append(l.SCOs, sco{
+				Identifier: fmt.Sprintf("sco-%d", p.n),
+				Title:      title,
+				Href:       href,
+				Files:      append([]string{href}, assets...),
+			})
+		}
+		p.lectures = append(p.lectures, l)
+	}
+	return p, nil
+}
+
+// addAssets adds the local files the markdown file or notebook of cb links to
+// or embeds, see render.LocalFiles, and returns their hrefs. They keep their
+// place next to the page so relative links work. A file several pages use is
+// added once.
+func (p *Package) addAssets(lectureDir, dir string, cb model.ContentBlockYaml) ([]string, error) {
+	if cb.Filename == "" || (cb.Type != "markdown" && cb.Type != "notebook") {
+		return nil, nil
+	}
+	var hrefs []string
+	for _, rel := range render.LocalFiles(lectureDir, []string{cb.Filename}, nil) {
+		if rel == filepath.Clean(filepath.FromSlash(cb.Filename)) {
+			// the page shows it
+			continue
+		}
+		href := path.Join(dir, filepath.ToSlash(rel))
+		hrefs = append(hrefs, href)
+		if p.hrefs[href] {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(lectureDir, rel))
+		if err != nil {
+			return nil, err
+		}
+		p.hrefs[href] = true
+		p.files = append(p.files, contentpkg.File{Href: href, Content: content})
+	}
+	return hrefs, nil
+}
+
+// renderBlock returns the page body for a content block, or nil if the
+// block type can't be shown as a page
+func renderBlock(lectureDir string, cb model.ContentBlockYaml, stripOutputs bool) ([]byte, error) {
+	var body []byte
+	if cb.Description != "" {
+		body = []byte("<p>" + html.EscapeString(cb.Description) + "</p>\n")
+	}
+
+	switch cb.Type {
+	case "markdown":
+		content, err := os.ReadFile(filepath.Join(lectureDir, cb.Filename))
+		if err != nil {
+			return nil, err
+		}
+		out, err := render.Markdown(content)
+		if err != nil {
+			return nil, err
+		}
+		return append(body, out...), nil
+	case "notebook":
+		nb, err := notebook.ReadFile(filepath.Join(lectureDir, cb.Filename))
+		if err != nil {
+			return nil, err
+		}
+		if stripOutputs {
+			nb.StripOutputs()
+		}
+		out, err := render.Notebook(nb)
+		if err != nil {
+			return nil, err
+		}
+		return append(body, out...), nil
+	case "video":
+		return append(body, render.Video(cb)...), nil
+	case "link":
+		link := fmt.Sprintf("<p><a href=\"%s\" target=\"_blank\">%s</a></p>\n", html.EscapeString(cb.URL), html.EscapeString(cb.URL))
+		return append(body, link...), nil
+	}
+	return nil, nil
+}
+
+// WriteZip writes the package, with its imsmanifest.xml, as a zip file
+func (p *Package) WriteZip(w io.Writer) error {
+	files := append([]contentpkg.File{{Href: apiScriptHref, Content: apiScript}}, p.files...)
+	return contentpkg.WriteZip(w, p.manifest(), files...)
+}
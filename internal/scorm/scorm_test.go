@@ -0,0 +1,114 @@
+package scorm
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sglyon/jupyteach/internal/model"
+)
+
+const testNotebook = `{
+ "cells": [
+  {"cell_type": "markdown", "metadata": {}, "source": ["# Hello"]},
+  {"cell_type": "code", "execution_count": 1, "metadata": {}, "source": ["print(1 < 2)"],
+   "outputs": [{"output_type": "stream", "name": "stdout", "text": ["True\n"]}]}
+ ],
+ "metadata": {"language_info": {"name": "python"}},
+ "nbformat": 4,
+ "nbformat_minor": 5
+}`
+
+func TestBuild(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"_course.yml": "slug: demo\nname: Demo\nlectures:\n- directory: intro\n- directory: empty\n",
+		"intro/_lecture.yml": `title: Intro
+content_blocks:
+- {type: notebook, title: Notebook, filename: intro.ipynb}
+- {type: markdown, title: Notes, filename: notes.md}
+- {type: video, title: Welcome, vimeo_video_id: "42"}
+- {type: link, title: Docs, url: "https://example.com"}
+- {type: quiz, title: Check}
+- {type: link, title: "???", url: "https://example.org"}
+`,
+		"intro/intro.ipynb":    testNotebook,
+		"intro/notes.md":       "Some *notes*\n\n![chart](figs/chart.png)\n",
+		"intro/figs/chart.png": "png",
+		"empty/_lecture.yml":   "title: Empty\ncontent_blocks: []\n",
+	}
+	for name, content := range files {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	course, err := model.ParseCourseYaml(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := Build(dir, course, model.ZipOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Skipped) != 1 || !strings.Contains(p.Skipped[0], "Check") {
+		t.Errorf("expected the quiz to be skipped, got %v", p.Skipped)
+	}
+
+	var buf bytes.Buffer
+	if err := p.WriteZip(&buf); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	contents := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := io.ReadAll(rc)
+		rc.Close()
+		contents[f.Name] = string(b)
+	}
+
+	checks := map[string][]string{
+		"intro/intro.html":     {`<script src="../scorm.js">`, "<h1>Hello</h1>", "print(1 &lt; 2)", "True"},
+		"intro/notes.html":     {"<em>notes</em>", `src="figs/chart.png"`},
+		"intro/figs/chart.png": {"png"},
+		"intro/welcome.html":   {"https://player.vimeo.com/video/42"},
+		"intro/docs.html":      {`href="https://example.com"`},
+		"intro/link-6.html":    {`href="https://example.org"`},
+		"scorm.js":             {"cmi.core.lesson_status"},
+		"imsmanifest.xml":      {`<file href="intro/figs/chart.png">`, `adlcp:scormtype="sco"`, `<schemaversion>1.2</schemaversion>`, "<title>Intro</title>"},
+	}
+	for name, wants := range checks {
+		content, ok := contents[name]
+		if !ok {
+			t.Errorf("missing %s", name)
+			continue
+		}
+		for _, want := range wants {
+			if !strings.Contains(content, want) {
+				t.Errorf("%s does not contain %q:\n%s", name, want, content)
+			}
+		}
+	}
+	if strings.Contains(contents["imsmanifest.xml"], "<title>Empty</title>") {
+		t.Error("lectures without pages should not be in the organization")
+	}
+	if err := xml.Unmarshal([]byte(contents["imsmanifest.xml"]), new(interface{})); err != nil {
+		t.Errorf("invalid manifest: %v", err)
+	}
+}
@@ -0,0 +1,105 @@
+package site
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/sglyon/jupyteach/internal/model"
+	"github.com/sglyon/jupyteach/internal/render"
+)
+
+// Build writes the site as static files to out: the course index, a page per
+// lecture, the stylesheet and the files of each lecture its content blocks
+// use. It returns the number of files written.
+func (s *Site) Build(out string) (int, error) {
+	written := 0
+	write := func(name string, content []byte) error {
+		p := filepath.Join(out, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			return err
+		}
+		written++
+		return os.WriteFile(p, content, 0o644)
+	}
+
+	index, err := s.Index()
+	if err != nil {
+		return written, err
+	}
+	if err := write("index.html", index); err != nil {
+		return written, err
+	}
+	style, err := Asset("style.css")
+	if err != nil {
+		return written, err
+	}
+	if err := write(filepath.Join(AssetDir, "style.css"), style); err != nil {
+		return written, err
+	}
+	// stop GitHub Pages from running jekyll, which hides directories
+	// starting with an underscore like AssetDir
+	if err := write(".nojekyll", nil); err != nil {
+		return written, err
+	}
+
+	_, links, err := s.lectures()
+	if err != nil {
+		return written, err
+	}
+	for _, l := range links {
+		page, err := s.Lecture(l.Directory)
+		if err != nil {
+			return written, err
+		}
+		if err := write(filepath.FromSlash(l.Href), page); err != nil {
+			return written, err
+		}
+
+		n, err := copyLectureFiles(filepath.Join(s.Dir, l.Directory), filepath.Join(out, l.Directory))
+		written += n
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+// copyLectureFiles copies the files students may see from a lecture
+// directory: the notebooks and markdown files of its content blocks and the
+// local files those link to or embed. Other files, like solutions or
+// grading scripts kept next to the lecture, are left out, as are
+// `_lecture.yml` and markdown quizzes since they hold the quiz solutions.
+func copyLectureFiles(lectureDir, out string) (int, error) {
+	private := map[string]bool{"_lecture.yml": true}
+	lecture, err := model.ParseLectureYaml(filepath.Join(lectureDir, "_lecture.yml"))
+	if err != nil {
+		return 0, err
+	}
+	var names []string
+	for _, cb := range lecture.ContentBlocks {
+		switch {
+		case cb.Filename == "":
+		case cb.Type == "quiz":
+			private[filepath.Clean(filepath.FromSlash(cb.Filename))] = true
+		case cb.Type == "notebook" || cb.Type == "markdown":
+			names = append(names, cb.Filename)
+		}
+	}
+
+	copied := 0
+	for _, rel := range render.LocalFiles(lectureDir, names, func(name string) bool { return private[name] }) {
+		content, err := os.ReadFile(filepath.Join(lectureDir, rel))
+		if err != nil {
+			return copied, err
+		}
+		dst := filepath.Join(out, rel)
+		if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+			return copied, err
+		}
+		if err := os.WriteFile(dst, content, 0o644); err != nil {
+			return copied, err
+		}
+		copied++
+	}
+	return copied, nil
+}
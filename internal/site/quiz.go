@@ -0,0 +1,65 @@
+package site
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"strings"
+
+	"github.com/sglyon/jupyteach/internal/model"
+	"github.com/sglyon/jupyteach/internal/render"
+)
+
+type quizQuestion struct {
+	Number       int
+	Name         string
+	Type         string
+	Points       int
+	Text         template.HTML
+	Options      []string
+	InputType    string
+	StartingCode string
+	Rows         int
+}
+
+type quizData struct {
+	StartCode string
+	Questions []quizQuestion
+}
+
+// renderQuiz shows a quiz as a form. Answers are not submitted anywhere, the
+// form only shows students what they will be asked.
+func renderQuiz(name string, quiz model.Quiz) (template.HTML, error) {
+	data := quizData{StartCode: quiz.StartCode}
+	for i, q := range quiz.Questions {
+		text, err := render.Markdown([]byte(q.QuestionText))
+		if err != nil {
+			return "", err
+		}
+		qq := quizQuestion{
+			Number:       i + 1,
+			Name:         fmt.Sprintf("%s-q%d", name, i+1),
+			Type:         q.QuestionType,
+			Points:       q.Points,
+			Text:         template.HTML(text),
+			StartingCode: q.StartingCode,
+			Rows:         strings.Count(q.StartingCode, "\n") + 3,
+		}
+		switch q.QuestionType {
+		case "single_selection":
+			qq.Options, qq.InputType = q.Options, "radio"
+		case "multiple_selection":
+			qq.Options, qq.InputType = q.Options, "checkbox"
+		}
+		if qq.Rows < 5 {
+			qq.Rows = 5
+		}
+		data.Questions = append(data.Questions, qq)
+	}
+
+	var buf bytes.Buffer
+	if err := baseTemplate.ExecuteTemplate(&buf, "quiz", data); err != nil {
+		return "", err
+	}
+	return template.HTML(buf.String()), nil
+}
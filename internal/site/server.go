@@ -0,0 +1,203 @@
+package site
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Server serves the pages of a site and, with LiveReload, tells open pages
+// to reload when a file of the course changes
+type Server struct {
+	Site *Site
+
+	// OnError is called when a page fails to render
+	OnError func(path string, err error)
+
+	mu      sync.Mutex
+	clients map[chan struct{}]struct{}
+}
+
+// NewServer returns a server for site
+func NewServer(site *Site) *Server {
+	return &Server{Site: site, clients: map[chan struct{}]struct{}{}}
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p := path.Clean("/" + r.URL.Path)
+	for _, part := range strings.Split(p, "/") {
+		if strings.HasPrefix(part, ".") {
+			http.NotFound(w, r)
+			return
+		}
+	}
+
+	switch {
+	case p == "/_site/events":
+		s.serveEvents(w, r)
+		return
+	case strings.HasPrefix(p, "/"+AssetDir+"/"):
+		s.serveAsset(w, r, strings.TrimPrefix(p, "/"+AssetDir+"/"))
+		return
+	case p == "/" || p == "/index.html":
+		s.servePage(w, r, s.Site.Index)
+		return
+	}
+
+	dir := strings.TrimPrefix(strings.TrimSuffix(p, "/index.html"), "/")
+	if dir != "" && (strings.HasSuffix(r.URL.Path, "/") || strings.HasSuffix(p, "/index.html")) {
+		s.servePage(w, r, func() ([]byte, error) { return s.Site.Lecture(filepath.FromSlash(dir)) })
+		return
+	}
+	if info, err := os.Stat(filepath.Join(s.Site.Dir, filepath.FromSlash(p))); err == nil && info.IsDir() {
+		http.Redirect(w, r, p+"/", http.StatusFound)
+		return
+	}
+	http.ServeFile(w, r, filepath.Join(s.Site.Dir, filepath.FromSlash(p)))
+}
+
+func (s *Server) servePage(w http.ResponseWriter, r *http.Request, build func() ([]byte, error)) {
+	content, err := build()
+	if errors.Is(err, os.ErrNotExist) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		if s.OnError != nil {
+			s.OnError(r.URL.Path, err)
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(content)
+}
+
+func (s *Server) serveAsset(w http.ResponseWriter, r *http.Request, name string) {
+	content, err := Asset(name)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	http.ServeContent(w, r, name, time.Time{}, bytes.NewReader(content))
+}
+
+// serveEvents streams a message to the page whenever the course changes
+func (s *Server) serveEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	flusher.Flush()
+
+	ch := make(chan struct{}, 1)
+	s.mu.Lock()
+	s.clients[ch] = struct{}{}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.clients, ch)
+		s.mu.Unlock()
+	}()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ch:
+			fmt.Fprint(w, "data: reload\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+// Reload tells every open page to reload
+func (s *Server) Reload() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for ch := range s.clients {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Watch reloads open pages whenever a file in the course directory changes,
+// until ctx is done. Changes arriving close together cause a single reload.
+// onChange, if not nil, is called with the name of a changed file.
+func (s *Server) Watch(ctx context.Context, onChange func(name string)) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	if err := watchTree(watcher, s.Site.Dir); err != nil {
+		return err
+	}
+
+	const debounce = 100 * time.Millisecond
+	timer := time.NewTimer(debounce)
+	timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-watcher.Errors:
+			return err
+		case event := <-watcher.Events:
+			if hidden(event.Name) {
+				continue
+			}
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					watchTree(watcher, event.Name)
+				}
+			}
+			if onChange != nil {
+				onChange(event.Name)
+			}
+			timer.Reset(debounce)
+		case <-timer.C:
+			s.Reload()
+		}
+	}
+}
+
+// hidden reports whether the file name is hidden (like .git) or an editor
+// swap file
+func hidden(name string) bool {
+	base := filepath.Base(name)
+	return strings.HasPrefix(base, ".") || strings.HasSuffix(base, "~")
+}
+
+// watchTree watches dir and every directory below it, except hidden ones
+func watchTree(watcher *fsnotify.Watcher, dir string) error {
+	return filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if p != dir && hidden(p) {
+			return filepath.SkipDir
+		}
+		return watcher.Add(p)
+	})
+}
@@ -0,0 +1,282 @@
+// Package site renders a course to HTML pages as students would see it. It
+// backs both the preview server and static site builds.
+package site
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html/template"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sglyon/jupyteach/internal/model"
+	"github.com/sglyon/jupyteach/internal/notebook"
+	"github.com/sglyon/jupyteach/internal/render"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+var (
+	baseTemplate    = template.Must(template.ParseFS(templateFS, "templates/layout.html", "templates/quiz.html"))
+	indexTemplate   = pageTemplate("index.html")
+	lectureTemplate = pageTemplate("lecture.html")
+)
+
+// pageTemplate adds the "content" of a page to the layout
+func pageTemplate(name string) *template.Template {
+	return template.Must(template.Must(baseTemplate.Clone()).ParseFS(templateFS, "templates/"+name))
+}
+
+// AssetDir is the directory, relative to the site root, holding the
+// stylesheet and scripts
+const AssetDir = "_site"
+
+// Assets lists the files served from AssetDir
+var Assets = []string{"style.css", "livereload.js"}
+
+// Asset returns the content of one of the Assets
+func Asset(name string) ([]byte, error) {
+	return templateFS.ReadFile("templates/" + name)
+}
+
+// Site renders the course in Dir
+type Site struct {
+	Dir string
+
+	// LiveReload adds a script to every page that reloads it when the
+	// preview server reports a change
+	LiveReload bool
+
+	// StripOutputs hides the outputs of notebook cells
+	StripOutputs bool
+
+	// AsOf, when set, shows only the lectures students can see at that time
+	AsOf time.Time
+}
+
+// page holds the fields shared by every page
+type page struct {
+	Title      string
+	CourseName string
+	Root       string
+	LiveReload bool
+	AsOf       string
+}
+
+func (s *Site) page(course *model.CourseYaml, title, root string) page {
+	p := page{
+		Title:      title,
+		CourseName: courseName(course),
+		Root:       root,
+		LiveReload: s.LiveReload,
+	}
+	if !s.AsOf.IsZero() {
+		p.AsOf = s.AsOf.Format("Mon 2006-01-02 15:04 MST")
+	}
+	return p
+}
+
+type lectureLink struct {
+	Directory   string
+	Title       string
+	Description string
+	AvailableAt string
+	Href        string
+}
+
+type indexPage struct {
+	page
+	Syllabus template.HTML
+	Lectures []lectureLink
+}
+
+type block struct {
+	ID          string
+	Type        string
+	Title       string
+	Description string
+	Body        template.HTML
+}
+
+type lecturePage struct {
+	page
+	Lecture lectureLink
+	Blocks  []block
+	Prev    *lectureLink
+	Next    *lectureLink
+}
+
+// LectureHref returns the path of a lecture page relative to the site root
+func LectureHref(dir string) string {
+	return path.Join(filepath.ToSlash(dir), "index.html")
+}
+
+func courseName(course *model.CourseYaml) string {
+	if course.Name != "" {
+		return course.Name
+	}
+	return course.Slug
+}
+
+// lectures returns the lectures of the course in order, leaving out those
+// not yet visible at AsOf
+func (s *Site) lectures() (*model.CourseYaml, []lectureLink, error) {
+	course, err := model.ParseCourseYaml(s.Dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	lectures := course.Lectures
+	if !s.AsOf.IsZero() {
+		if lectures, err = course.VisibleLectures(s.AsOf); err != nil {
+			return nil, nil, err
+		}
+	}
+	links := make([]lectureLink, len(lectures))
+	for i, cl := range lectures {
+		links[i] = lectureLink{
+			Directory:   cl.Directory,
+			Title:       cl.Directory,
+			AvailableAt: cl.AvailableAt,
+			Href:        LectureHref(cl.Directory),
+		}
+		lecture, err := model.ParseLectureYaml(filepath.Join(s.Dir, cl.Directory, "_lecture.yml"))
+		if err != nil {
+			continue
+		}
+		if lecture.Title != "" {
+			links[i].Title = lecture.Title
+		}
+		links[i].Description = lecture.Description
+	}
+	return course, links, nil
+}
+
+func execute(t *template.Template, data interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := t.ExecuteTemplate(&buf, "layout", data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Index renders the course page with the syllabus and the list of lectures
+func (s *Site) Index() ([]byte, error) {
+	course, links, err := s.lectures()
+	if err != nil {
+		return nil, err
+	}
+
+	data := indexPage{
+		page:     s.page(course, courseName(course), ""),
+		Lectures: links,
+	}
+	if syllabus, err := os.ReadFile(filepath.Join(s.Dir, "syllabus.md")); err == nil {
+		out, err := render.Markdown(syllabus)
+		if err != nil {
+			return nil, err
+		}
+		data.Syllabus = template.HTML(out)
+	}
+
+	return execute(indexTemplate, data)
+}
+
+// Lecture renders the page of the lecture in directory dir. It returns
+// os.ErrNotExist if dir is not a lecture of the course or is not visible at
+// AsOf.
+func (s *Site) Lecture(dir string) ([]byte, error) {
+	course, links, err := s.lectures()
+	if err != nil {
+		return nil, err
+	}
+	index := -1
+	for i, l := range links {
+		if l.Directory == dir {
+			index = i
+		}
+	}
+	if index < 0 {
+		return nil, os.ErrNotExist
+	}
+
+	lectureDir := filepath.Join(s.Dir, dir)
+	lecture, err := model.ParseLectureYaml(filepath.Join(lectureDir, "_lecture.yml"))
+	if err != nil {
+		return nil, err
+	}
+
+	root := strings.Repeat("../", strings.Count(LectureHref(dir), "/"))
+	data := lecturePage{
+		page:    s.page(course, links[index].Title+" - "+courseName(course), root),
+		Lecture: links[index],
+	}
+	if index > 0 {
+		prev := links[index-1]
+		prev.Href = root + prev.Href
+		data.Prev = &prev
+	}
+	if index < len(links)-1 {
+		next := links[index+1]
+		next.Href = root + next.Href
+		data.Next = &next
+	}
+
+	for i, cb := range lecture.ContentBlocks {
+		b := block{
+			ID:          fmt.Sprintf("block-%d", i+1),
+			Type:        cb.Type,
+			Title:       cb.Title,
+			Description: cb.Description,
+		}
+		body, err := s.renderBlock(lectureDir, i, cb)
+		if err != nil {
+			// show the problem in place so the rest of the lecture can still be previewed
+			body = template.HTML(fmt.Sprintf(`<p class="error">%s</p>`, template.HTMLEscapeString(err.Error())))
+		}
+		b.Body = body
+		data.Blocks = append(data.Blocks, b)
+	}
+
+	return execute(lectureTemplate, data)
+}
+
+func (s *Site) renderBlock(lectureDir string, index int, cb model.ContentBlockYaml) (template.HTML, error) {
+	switch cb.Type {
+	case "markdown":
+		content, err := os.ReadFile(filepath.Join(lectureDir, cb.Filename))
+		if err != nil {
+			return "", err
+		}
+		out, err := render.Markdown(content)
+		return template.HTML(out), err
+	case "notebook":
+		nb, err := notebook.ReadFile(filepath.Join(lectureDir, cb.Filename))
+		if err != nil {
+			return "", err
+		}
+		if s.StripOutputs {
+			nb.StripOutputs()
+		}
+		out, err := render.Notebook(nb)
+		return template.HTML(out), err
+	case "video":
+		return template.HTML(render.Video(cb)), nil
+	case "link":
+		return template.HTML(fmt.Sprintf(
+			`<p><a href="%s" target="_blank">%s</a></p>`,
+			template.HTMLEscapeString(cb.URL), template.HTMLEscapeString(cb.URL),
+		)), nil
+	case "quiz":
+		quiz, err := cb.LoadQuiz(lectureDir)
+		if err != nil {
+			return "", err
+		}
+		return renderQuiz(fmt.Sprintf("quiz-%d", index+1), quiz)
+	}
+	return "", fmt.Errorf("unknown content block type %q", cb.Type)
+}
@@ -0,0 +1,180 @@
+package site
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeCourse(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"_course.yml": "slug: demo\nname: Demo\nlectures:\n- directory: intro\n- directory: loops\n  available_at: \"2024-02-01T09:00:00Z\"\n",
+		"syllabus.md": "Welcome to *Demo*\n",
+		"intro/_lecture.yml": `title: Intro
+content_blocks:
+- {type: markdown, title: Notes, filename: notes.md}
+- {type: video, title: Welcome, youtube_video_id: abc}
+- {type: markdown, title: Missing, filename: missing.md}
+- type: quiz
+  title: Check
+  quiz:
+    questions:
+    - {question_type: single_selection, question_text: Pick, points: 1, options: ["a", "b"], solution: a}
+    - {question_type: code, question_text: Write it, points: 2, starting_code: "x = 1", solution: "x = 2", test_code: "assert x == 2"}
+`,
+		"intro/notes.md":     "Some <b>notes</b> and ![pic](pic.png)\n",
+		"intro/pic.png":      "png",
+		"loops/_lecture.yml": "title: Loops\ncontent_blocks: []\n",
+	}
+	for name, content := range files {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func get(t *testing.T, server http.Handler, url string) (int, string) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, httptest.NewRequest("GET", url, nil))
+	body, _ := io.ReadAll(rec.Result().Body)
+	return rec.Code, string(body)
+}
+
+func TestServer(t *testing.T) {
+	server := NewServer(&Site{Dir: writeCourse(t), LiveReload: true})
+
+	code, body := get(t, server, "/")
+	if code != http.StatusOK {
+		t.Fatalf("index returned %d: %s", code, body)
+	}
+	for _, want := range []string{"<em>Demo</em>", `href="intro/index.html">Intro`, `href="loops/index.html">Loops`, "available 2024-02-01T09:00:00Z", "livereload.js"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("index does not contain %q:\n%s", want, body)
+		}
+	}
+	if strings.Index(body, "Intro") > strings.Index(body, "Loops") {
+		t.Error("lectures are not in course order")
+	}
+
+	code, body = get(t, server, "/intro/")
+	if code != http.StatusOK {
+		t.Fatalf("lecture returned %d: %s", code, body)
+	}
+	for _, want := range []string{
+		`href="../_site/style.css"`,
+		"<b>notes</b>",
+		"https://www.youtube.com/embed/abc",
+		`class="error"`,
+		`<input type="radio" name="quiz-4-q1" value="0"> a`,
+		`<textarea name="quiz-4-q2" rows="5" spellcheck="false">x = 1</textarea>`,
+		`href="../loops/index.html">Loops`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("lecture does not contain %q:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, "x = 2") {
+		t.Error("lecture page shows the solution of a question")
+	}
+
+	if code, _ := get(t, server, "/intro/pic.png"); code != http.StatusOK {
+		t.Errorf("lecture files should be served, got %d", code)
+	}
+	if code, _ := get(t, server, "/_site/style.css"); code != http.StatusOK {
+		t.Errorf("stylesheet should be served, got %d", code)
+	}
+	if code, _ := get(t, server, "/nope/"); code != http.StatusNotFound {
+		t.Errorf("unknown lecture should be not found, got %d", code)
+	}
+	if code, _ := get(t, server, "/.git/config"); code != http.StatusNotFound {
+		t.Errorf("hidden files should not be served, got %d", code)
+	}
+}
+
+func TestServerAsOf(t *testing.T) {
+	server := NewServer(&Site{Dir: writeCourse(t), AsOf: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)})
+
+	_, body := get(t, server, "/")
+	if strings.Contains(body, "Loops") {
+		t.Error("lectures not yet available should not be listed")
+	}
+	if !strings.Contains(body, "student view as of") {
+		t.Error("index should say it shows the student view")
+	}
+	if code, _ := get(t, server, "/loops/"); code != http.StatusNotFound {
+		t.Errorf("lectures not yet available should not be found, got %d", code)
+	}
+	if _, body := get(t, server, "/intro/"); strings.Contains(body, "Loops") {
+		t.Error("lectures not yet available should not be linked")
+	}
+}
+
+func TestBuild(t *testing.T) {
+	dir := writeCourse(t)
+	if err := os.WriteFile(filepath.Join(dir, "intro", "quiz.md"), []byte("## Q\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.OpenFile(filepath.Join(dir, "intro", "_lecture.yml"), os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("- {type: quiz, title: Markdown quiz, filename: quiz.md}\n")
+	f.Close()
+	// files no block uses or links to, like solutions, stay private
+	for name, content := range map[string]string{
+		"intro/solutions.ipynb": "{}",
+		"intro/grade.py":        "print('grading')",
+		"intro/data/raw.csv":    "a,b",
+		"intro/.secret.png":     "png",
+	} {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "intro", "notes.md"), []byte("Some <b>notes</b> and ![pic](pic.png), <img src='.secret.png'>, [data](data/table.csv?raw=1) and [docs](https://example.com/x.png)\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "intro", "data", "table.csv"), []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	out := t.TempDir()
+	if _, err := (&Site{Dir: dir}).Build(out); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"index.html", "_site/style.css", ".nojekyll", "intro/index.html", "intro/notes.md", "intro/pic.png", "intro/data/table.csv", "loops/index.html"} {
+		if _, err := os.Stat(filepath.Join(out, name)); err != nil {
+			t.Errorf("missing %s", name)
+		}
+	}
+	for _, name := range []string{
+		"_course.yml", "intro/_lecture.yml", "intro/quiz.md", "_site/livereload.js",
+		"intro/solutions.ipynb", "intro/grade.py", "intro/data/raw.csv", "intro/.secret.png",
+	} {
+		if _, err := os.Stat(filepath.Join(out, name)); err == nil {
+			t.Errorf("%s should not be part of the site", name)
+		}
+	}
+
+	page, _ := os.ReadFile(filepath.Join(out, "intro", "index.html"))
+	if strings.Contains(string(page), "livereload") {
+		t.Error("static pages should not reload")
+	}
+}
@@ -0,0 +1,7 @@
+// Reloads the page when the preview server reports a change to the course
+(function () {
+  var source = new EventSource("/_site/events");
+  source.onmessage = function () {
+    window.location.reload();
+  };
+})();